@@ -0,0 +1,126 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// AddressesTransactions is the merged result of a multi-address history query
+type AddressesTransactions struct {
+	// Transactions deduplicated by TransactionID and ordered by (BlockHeight, BlockIndex),
+	// with unconfirmed transactions last
+	Transactions []*models.AddressTx
+	// Addresses lists the queried addresses each transaction relates to, keyed by transaction ID
+	Addresses map[string][]string
+	// Errors holds the error for each address that could not be fetched
+	Errors map[string]error
+}
+
+// Err joins the per-address errors, or returns nil if every address succeeded
+func (r *AddressesTransactions) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	addresses := make([]string, 0, len(r.Errors))
+	for address := range r.Errors {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	errs := make([]error, 0, len(addresses))
+	for _, address := range addresses {
+		errs = append(errs, fmt.Errorf("address %s: %w", address, r.Errors[address]))
+	}
+	return errors.Join(errs...)
+}
+
+// GetAddressesTransactions gets the transaction metadata for many addresses concurrently,
+// within the transport's concurrency limit. A failed address is reported in the result's
+// Errors and does not fail the batch; the returned error is only set for invalid arguments
+// or a cancelled context.
+func (jb *Client) GetAddressesTransactions(ctx context.Context, addresses []string, fromHeight uint32) (*AddressesTransactions, error) {
+	result := &AddressesTransactions{
+		Addresses: make(map[string][]string),
+		Errors:    make(map[string]error),
+	}
+	byID := make(map[string]*models.AddressTx)
+
+	err := jb.StreamAddressesTransactions(ctx, addresses, fromHeight, func(address string, txs []*models.AddressTx, err error) {
+		if err != nil {
+			result.Errors[address] = err
+			return
+		}
+		for _, tx := range txs {
+			if _, ok := byID[tx.TransactionID]; !ok {
+				byID[tx.TransactionID] = tx
+				result.Transactions = append(result.Transactions, tx)
+			}
+			result.Addresses[tx.TransactionID] = append(result.Addresses[tx.TransactionID], address)
+		}
+	})
+	if err != nil {
+		return result, err
+	}
+
+	sort.Slice(result.Transactions, func(i, j int) bool {
+		return addressTxLess(result.Transactions[i], result.Transactions[j])
+	})
+	for _, related := range result.Addresses {
+		sort.Strings(related)
+	}
+	return result, nil
+}
+
+// StreamAddressesTransactions gets the transaction metadata for many addresses concurrently and
+// calls handler with each address's transactions (or error) as soon as they arrive. The handler
+// is never called concurrently. Results are not deduplicated across addresses.
+func (jb *Client) StreamAddressesTransactions(ctx context.Context, addresses []string, fromHeight uint32,
+	handler func(address string, txs []*models.AddressTx, err error)) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if handler == nil {
+		return errors.New("handler cannot be nil")
+	}
+
+	unique := make([]string, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		if address == "" {
+			return errors.New("address cannot be empty")
+		}
+		if _, ok := seen[address]; !ok {
+			seen[address] = struct{}{}
+			unique = append(unique, address)
+		}
+	}
+
+	var mu sync.Mutex
+	fanOut(ctx, len(unique), func(ctx context.Context, i int) {
+		txs, err := jb.transport.GetAddressTransactions(ctx, unique[i], fromHeight)
+		mu.Lock()
+		defer mu.Unlock()
+		handler(unique[i], txs, err)
+	})
+
+	return ctx.Err()
+}
+
+// addressTxLess orders by block height and index, with unconfirmed transactions last
+func addressTxLess(a, b *models.AddressTx) bool {
+	if (a.BlockHeight == 0) != (b.BlockHeight == 0) {
+		return b.BlockHeight == 0
+	}
+	if a.BlockHeight != b.BlockHeight {
+		return a.BlockHeight < b.BlockHeight
+	}
+	if a.BlockIndex != b.BlockIndex {
+		return a.BlockIndex < b.BlockIndex
+	}
+	return a.TransactionID < b.TransactionID
+}
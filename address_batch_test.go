@@ -0,0 +1,115 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAddressesTransactions(t *testing.T) {
+	histories := map[string][]*models.AddressTx{
+		"a": {
+			{TransactionID: "tx2", BlockHeight: 10, BlockIndex: 5},
+			{TransactionID: "tx1", BlockHeight: 10, BlockIndex: 1},
+		},
+		"b": {
+			{TransactionID: "mempool"},
+			{TransactionID: "tx2", BlockHeight: 10, BlockIndex: 5},
+			{TransactionID: "tx0", BlockHeight: 9, BlockIndex: 7},
+		},
+	}
+
+	var inFlight, maxInFlight int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/address/get/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		address := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/address/get/"), "/")[0]
+		history, ok := histories[address]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(history)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient), WithMaxConcurrentRequests(2))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("merges, dedupes and orders", func(t *testing.T) {
+		result, err := client.GetAddressesTransactions(ctx, []string{"a", "b", "bad", "a"}, 0)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, tx := range result.Transactions {
+			ids = append(ids, tx.TransactionID)
+		}
+		assert.Equal(t, []string{"tx0", "tx1", "tx2", "mempool"}, ids)
+		assert.Equal(t, []string{"a", "b"}, result.Addresses["tx2"])
+		assert.Equal(t, []string{"b"}, result.Addresses["mempool"])
+
+		require.Len(t, result.Errors, 1)
+		assert.Error(t, result.Errors["bad"])
+		assert.ErrorContains(t, result.Err(), "address bad")
+	})
+
+	t.Run("respects the concurrency limit", func(t *testing.T) {
+		addresses := make([]string, 20)
+		for i := range addresses {
+			addresses[i] = "a"
+			if i%2 == 0 {
+				addresses[i] = "bad" + string(rune('a'+i))
+			}
+		}
+		atomic.StoreInt32(&maxInFlight, 0)
+		_, err := client.GetAddressesTransactions(ctx, append(addresses, "a", "b", "c", "d"), 0)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	})
+
+	t.Run("streams results", func(t *testing.T) {
+		got := make(map[string]int)
+		err := client.StreamAddressesTransactions(ctx, []string{"a", "b"}, 0, func(address string, txs []*models.AddressTx, err error) {
+			require.NoError(t, err)
+			got[address] = len(txs)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 2, "b": 3}, got)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := client.GetAddressesTransactions(getNilContext(), []string{"a"}, 0)
+		require.Error(t, err)
+		_, err = client.GetAddressesTransactions(ctx, []string{""}, 0)
+		require.Error(t, err)
+		err = client.StreamAddressesTransactions(ctx, []string{"a"}, 0, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := client.GetAddressesTransactions(cancelled, []string{"a", "b"}, 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
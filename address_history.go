@@ -0,0 +1,237 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// DefaultAddressHistoryPageSize is the number of transactions requested per page
+const DefaultAddressHistoryPageSize = 1000
+
+var (
+	// ErrInvalidCursor is returned when an address history cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid address history cursor")
+	// ErrPageTooSmall is returned when a single block holds more transactions for the address
+	// than fit in one page, so paging by height cannot make progress
+	ErrPageTooSmall = errors.New("address history page size too small to make progress")
+)
+
+// AddressHistoryOptions configures an address history iterator
+type AddressHistoryOptions struct {
+	FromHeight uint32 // Height to start from, ignored if Cursor is set
+	Cursor     string // Cursor returned by HistoryIterator.Cursor to resume after the last transaction
+	PageSize   uint   // Transactions per request (DefaultAddressHistoryPageSize if 0)
+}
+
+// HistoryIterator walks an address history page by page, decoding each response as it streams in.
+//
+//	it, err := client.AddressHistory(ctx, address, nil)
+//	defer it.Close()
+//	for it.Next() {
+//		tx := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// Pages are requested from the height of the last transaction seen, skipping anything at or
+// before its (BlockHeight, BlockIndex). Unconfirmed transactions (height 0) come after the
+// confirmed ones and are returned once but do not move the cursor, so only those in the pages
+// requested from the last height are seen.
+type HistoryIterator[T any] struct {
+	ctx      context.Context
+	pageSize uint
+	open     func(ctx context.Context, fromHeight uint32, limit uint) (*transports.ArrayReader[T], error)
+	position func(item *T) (height uint32, index uint64, txID string)
+
+	reader      *transports.ArrayReader[T]
+	height      uint32
+	index       uint64
+	hasPosition bool
+	pageCount   uint
+	pageNew     uint
+	pageSeen    uint // unconfirmed transactions in the page that were already returned
+	unconfirmed map[string]struct{}
+
+	current *T
+	err     error
+	done    bool
+}
+
+// AddressHistory returns an iterator over the transaction metadata for the given address
+func (jb *Client) AddressHistory(ctx context.Context, address string, options *AddressHistoryOptions) (*HistoryIterator[models.AddressTx], error) {
+	return newHistoryIterator(ctx, address, options,
+		func(ctx context.Context, fromHeight uint32, limit uint) (*transports.ArrayReader[models.AddressTx], error) {
+			streams, ok := transports.As[transports.StreamService](jb.transport)
+			if !ok {
+				return nil, transports.ErrNotSupported
+			}
+			return streams.StreamAddressTransactions(ctx, address, fromHeight, limit)
+		},
+		func(tx *models.AddressTx) (uint32, uint64, string) {
+			return tx.BlockHeight, tx.BlockIndex, tx.TransactionID
+		},
+	)
+}
+
+// AddressHistoryDetails returns an iterator over the full transactions for the given address
+func (jb *Client) AddressHistoryDetails(ctx context.Context, address string, options *AddressHistoryOptions) (*HistoryIterator[models.Transaction], error) {
+	return newHistoryIterator(ctx, address, options,
+		func(ctx context.Context, fromHeight uint32, limit uint) (*transports.ArrayReader[models.Transaction], error) {
+			streams, ok := transports.As[transports.StreamService](jb.transport)
+			if !ok {
+				return nil, transports.ErrNotSupported
+			}
+			return streams.StreamAddressTransactionDetails(ctx, address, fromHeight, limit)
+		},
+		func(tx *models.Transaction) (uint32, uint64, string) {
+			return tx.BlockHeight, tx.BlockIndex, tx.ID
+		},
+	)
+}
+
+// newHistoryIterator validates the arguments and creates an iterator
+func newHistoryIterator[T any](
+	ctx context.Context,
+	address string,
+	options *AddressHistoryOptions,
+	open func(ctx context.Context, fromHeight uint32, limit uint) (*transports.ArrayReader[T], error),
+	position func(item *T) (uint32, uint64, string),
+) (*HistoryIterator[T], error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	if address == "" {
+		return nil, errors.New("address cannot be empty")
+	}
+	if options == nil {
+		options = &AddressHistoryOptions{}
+	}
+
+	it := &HistoryIterator[T]{
+		ctx:         ctx,
+		pageSize:    options.PageSize,
+		open:        open,
+		position:    position,
+		height:      options.FromHeight,
+		unconfirmed: make(map[string]struct{}),
+	}
+	if it.pageSize == 0 {
+		it.pageSize = DefaultAddressHistoryPageSize
+	}
+	if options.Cursor != "" {
+		var err error
+		if it.height, it.index, it.hasPosition, err = decodeHistoryCursor(options.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return it, nil
+}
+
+// Next advances to the next transaction, returning false at the end of the history or on error
+func (it *HistoryIterator[T]) Next() bool {
+	for !it.done && it.err == nil {
+		if it.reader == nil {
+			if it.reader, it.err = it.open(it.ctx, it.height, it.pageSize); it.err != nil {
+				return false
+			}
+			it.pageCount, it.pageNew, it.pageSeen = 0, 0, 0
+		}
+
+		item, err := it.reader.Next()
+		if errors.Is(err, io.EOF) {
+			_ = it.reader.Close()
+			it.reader = nil
+			switch {
+			case it.pageCount < it.pageSize:
+				it.done = true
+			case it.pageNew == 0 && it.pageSeen > 0:
+				// The page reached the unconfirmed transactions, all returned before
+				it.done = true
+			case it.pageNew == 0:
+				it.err = fmt.Errorf("%w: more than %d transactions at height %d", ErrPageTooSmall, it.pageSize, it.height)
+			}
+			continue
+		} else if err != nil {
+			_ = it.reader.Close()
+			it.reader = nil
+			it.err = err
+			return false
+		}
+		it.pageCount++
+
+		height, index, txID := it.position(item)
+		if height == 0 {
+			if _, ok := it.unconfirmed[txID]; ok {
+				it.pageSeen++
+				continue
+			}
+			it.unconfirmed[txID] = struct{}{}
+		} else {
+			if it.hasPosition && (height < it.height || (height == it.height && index <= it.index)) {
+				continue
+			}
+			it.height, it.index, it.hasPosition = height, index, true
+		}
+
+		it.pageNew++
+		it.current = item
+		return true
+	}
+	return false
+}
+
+// Value returns the current transaction
+func (it *HistoryIterator[T]) Value() *T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *HistoryIterator[T]) Err() error {
+	return it.err
+}
+
+// Cursor returns an opaque cursor that resumes after the last confirmed transaction returned
+func (it *HistoryIterator[T]) Cursor() string {
+	raw := strconv.FormatUint(uint64(it.height), 10)
+	if it.hasPosition {
+		raw += ":" + strconv.FormatUint(it.index, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Close releases the open response, if any. It is safe to call more than once.
+func (it *HistoryIterator[T]) Close() error {
+	it.done = true
+	if it.reader == nil {
+		return nil
+	}
+	err := it.reader.Close()
+	it.reader = nil
+	return err
+}
+
+// decodeHistoryCursor parses a cursor created by HistoryIterator.Cursor
+func decodeHistoryCursor(cursor string) (height uint32, index uint64, hasPosition bool, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, false, ErrInvalidCursor
+	}
+	heightPart, indexPart, hasPosition := strings.Cut(string(raw), ":")
+	h, err := strconv.ParseUint(heightPart, 10, 32)
+	if err != nil {
+		return 0, 0, false, ErrInvalidCursor
+	}
+	if hasPosition {
+		if index, err = strconv.ParseUint(indexPart, 10, 64); err != nil {
+			return 0, 0, false, ErrInvalidCursor
+		}
+	}
+	return uint32(h), index, hasPosition, nil
+}
@@ -0,0 +1,194 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHistoryServer serves the given history for an address, honouring fromHeight and limit
+func newHistoryServer(t *testing.T, history []*models.AddressTx, requests *[]string) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/address/get/addr/", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RequestURI())
+		fromHeight, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/v1/address/get/addr/"), 10, 32)
+		require.NoError(t, err)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		page := make([]*models.AddressTx, 0)
+		for _, tx := range history {
+			if tx.BlockHeight != 0 && tx.BlockHeight < uint32(fromHeight) {
+				continue
+			}
+			if limit > 0 && len(page) == limit {
+				break
+			}
+			page = append(page, tx)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	mux.HandleFunc("/v1/address/transactions/addr/0", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*models.Transaction{{ID: "a", BlockHeight: 1}, {ID: "b", BlockHeight: 2}})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient))
+	require.NoError(t, err)
+	return client
+}
+
+func testHistory() []*models.AddressTx {
+	var history []*models.AddressTx
+	for height := uint32(1); height <= 4; height++ {
+		for index := uint64(0); index < 3; index++ {
+			history = append(history, &models.AddressTx{
+				TransactionID: fmt.Sprintf("%d-%d", height, index),
+				BlockHeight:   height,
+				BlockIndex:    index,
+			})
+		}
+	}
+	return history
+}
+
+func collectHistory(t *testing.T, it *HistoryIterator[models.AddressTx], max int) []string {
+	t.Helper()
+	var ids []string
+	for len(ids) < max && it.Next() {
+		ids = append(ids, it.Value().TransactionID)
+	}
+	return ids
+}
+
+func TestAddressHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("validation", func(t *testing.T) {
+		client, err := New(WithHTTP("localhost"))
+		require.NoError(t, err)
+		_, err = client.AddressHistory(getNilContext(), "addr", nil)
+		require.Error(t, err)
+		_, err = client.AddressHistory(ctx, "", nil)
+		require.Error(t, err)
+		_, err = client.AddressHistory(ctx, "addr", &AddressHistoryOptions{Cursor: "!!"})
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("pages through the history", func(t *testing.T) {
+		var requests []string
+		client := newHistoryServer(t, testHistory(), &requests)
+
+		it, err := client.AddressHistory(ctx, "addr", &AddressHistoryOptions{PageSize: 5})
+		require.NoError(t, err)
+		defer it.Close()
+
+		ids := collectHistory(t, it, 100)
+		require.NoError(t, it.Err())
+		assert.Len(t, ids, 12)
+		assert.Equal(t, "1-0", ids[0])
+		assert.Equal(t, "4-2", ids[11])
+		assert.Equal(t, "/v1/address/get/addr/0?limit=5", requests[0])
+		assert.Equal(t, "/v1/address/get/addr/2?limit=5", requests[1])
+	})
+
+	t.Run("requests can be made while iterating", func(t *testing.T) {
+		var requests []string
+		client := newHistoryServer(t, testHistory(), &requests)
+		client.transport.SetMaxConcurrentRequests(1)
+
+		nested, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		it, err := client.AddressHistory(nested, "addr", &AddressHistoryOptions{PageSize: 5})
+		require.NoError(t, err)
+		defer it.Close()
+		require.True(t, it.Next())
+		txs, err := client.GetAddressTransactionDetails(nested, "addr", 0)
+		require.NoError(t, err)
+		assert.Len(t, txs, 2)
+	})
+
+	t.Run("resumes from a cursor", func(t *testing.T) {
+		var requests []string
+		client := newHistoryServer(t, testHistory(), &requests)
+
+		it, err := client.AddressHistory(ctx, "addr", &AddressHistoryOptions{PageSize: 4})
+		require.NoError(t, err)
+		first := collectHistory(t, it, 5)
+		cursor := it.Cursor()
+		require.NoError(t, it.Close())
+		assert.False(t, it.Next())
+
+		it, err = client.AddressHistory(ctx, "addr", &AddressHistoryOptions{Cursor: cursor, PageSize: 4})
+		require.NoError(t, err)
+		rest := collectHistory(t, it, 100)
+		require.NoError(t, it.Err())
+
+		assert.Equal(t, "2-1", first[4])
+		assert.Equal(t, "2-2", rest[0])
+		assert.Len(t, append(first, rest...), 12)
+	})
+
+	t.Run("unconfirmed transactions are returned once", func(t *testing.T) {
+		var requests []string
+		history := append(testHistory(), &models.AddressTx{TransactionID: "mempool"})
+		client := newHistoryServer(t, history, &requests)
+
+		it, err := client.AddressHistory(ctx, "addr", &AddressHistoryOptions{PageSize: 13})
+		require.NoError(t, err)
+		ids := collectHistory(t, it, 100)
+		require.NoError(t, it.Err())
+		assert.Len(t, ids, 13)
+		assert.Equal(t, "mempool", ids[12])
+	})
+
+	t.Run("unconfirmed transactions filling pages", func(t *testing.T) {
+		var requests []string
+		history := testHistory()
+		for i := 0; i < 6; i++ {
+			history = append(history, &models.AddressTx{TransactionID: fmt.Sprintf("mempool-%d", i)})
+		}
+		client := newHistoryServer(t, history, &requests)
+
+		it, err := client.AddressHistory(ctx, "addr", &AddressHistoryOptions{PageSize: 5})
+		require.NoError(t, err)
+		ids := collectHistory(t, it, 100)
+		require.NoError(t, it.Err())
+		assert.Equal(t, []string{"4-2", "mempool-0", "mempool-1"}, ids[11:])
+	})
+
+	t.Run("page too small", func(t *testing.T) {
+		var requests []string
+		client := newHistoryServer(t, testHistory(), &requests)
+
+		it, err := client.AddressHistory(ctx, "addr", &AddressHistoryOptions{PageSize: 2})
+		require.NoError(t, err)
+		collectHistory(t, it, 100)
+		require.ErrorIs(t, it.Err(), ErrPageTooSmall)
+	})
+
+	t.Run("details", func(t *testing.T) {
+		var requests []string
+		client := newHistoryServer(t, nil, &requests)
+
+		it, err := client.AddressHistoryDetails(ctx, "addr", nil)
+		require.NoError(t, err)
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Value().ID)
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []string{"a", "b"}, ids)
+	})
+}
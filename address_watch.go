@@ -0,0 +1,275 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// DefaultWatchPollInterval is how often WatchAddress checks the chain tip
+const DefaultWatchPollInterval = 10 * time.Second
+
+// CursorStore persists address history cursors so a watcher can resume after a restart.
+// Implementations must be safe for concurrent use.
+type CursorStore interface {
+	// LoadCursor returns the stored cursor for the key, or "" if there is none
+	LoadCursor(ctx context.Context, key string) (string, error)
+	// SaveCursor stores the cursor for the key
+	SaveCursor(ctx context.Context, key string, cursor string) error
+}
+
+// WatchAddressOptions configures Client.WatchAddress
+type WatchAddressOptions struct {
+	PollInterval time.Duration   // How often to check for a new chain tip (DefaultWatchPollInterval if 0)
+	PageSize     uint            // Transactions per request (DefaultAddressHistoryPageSize if 0)
+	CursorStore  CursorStore     // Where progress is stored, keyed by address (in memory if nil)
+	OnError      func(err error) // Called with errors that are retried on the next poll
+}
+
+// WatchAddress follows an address: it backfills the history from fromHeight, then fetches new
+// transactions every time the chain tip changes, calling handler once for each transaction.
+//
+// Progress is saved to the cursor store after every delivered confirmed transaction; a stored
+// cursor takes precedence over fromHeight. If handler returns an error, WatchAddress stops and
+// returns it, and that transaction is delivered again on the next run. Unconfirmed transactions
+// are delivered once per run and are not delivered again when they confirm.
+//
+// WatchAddress blocks until ctx is done or the handler fails.
+func (jb *Client) WatchAddress(ctx context.Context, address string, fromHeight uint32,
+	handler func(tx *models.AddressTx) error, options *WatchAddressOptions) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if handler == nil {
+		return errors.New("handler cannot be nil")
+	}
+	if options == nil {
+		options = &WatchAddressOptions{}
+	}
+	store := options.CursorStore
+	if store == nil {
+		store = NewMemoryCursorStore()
+	}
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = DefaultWatchPollInterval
+	}
+
+	cursor, err := store.LoadCursor(ctx, address)
+	if err != nil {
+		return fmt.Errorf("load cursor: %w", err)
+	}
+
+	w := &addressWatcher{
+		client:    jb,
+		address:   address,
+		handler:   handler,
+		store:     store,
+		options:   options,
+		cursor:    cursor,
+		from:      fromHeight,
+		delivered: make(map[string]struct{}),
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastTip := ""
+	for {
+		tip, err := jb.GetChainTip(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			w.reportError(fmt.Errorf("get chain tip: %w", err))
+		case tip != nil && tip.Hash != lastTip:
+			if err = w.fetch(ctx); err != nil {
+				var handlerErr *watchHandlerError
+				if errors.As(err, &handlerErr) {
+					return handlerErr.err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				w.reportError(err)
+			} else {
+				lastTip = tip.Hash
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// addressWatcher is the state of a single WatchAddress call
+type addressWatcher struct {
+	client    *Client
+	address   string
+	handler   func(tx *models.AddressTx) error
+	store     CursorStore
+	options   *WatchAddressOptions
+	cursor    string
+	from      uint32
+	delivered map[string]struct{} // unconfirmed transactions already delivered
+}
+
+// watchHandlerError marks an error returned by the handler, which stops the watcher
+type watchHandlerError struct {
+	err error
+}
+
+func (e *watchHandlerError) Error() string { return e.err.Error() }
+
+// fetch delivers every transaction after the cursor
+func (w *addressWatcher) fetch(ctx context.Context) error {
+	it, err := w.client.AddressHistory(ctx, w.address, &AddressHistoryOptions{
+		FromHeight: w.from,
+		Cursor:     w.cursor,
+		PageSize:   w.options.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = it.Close()
+	}()
+
+	for it.Next() {
+		tx := it.Value()
+		if _, seen := w.delivered[tx.TransactionID]; !seen {
+			if err = w.handler(tx); err != nil {
+				return &watchHandlerError{err: err}
+			}
+		}
+		if tx.BlockHeight != 0 {
+			delete(w.delivered, tx.TransactionID)
+		} else {
+			w.delivered[tx.TransactionID] = struct{}{}
+		}
+
+		if cursor := it.Cursor(); tx.BlockHeight != 0 && cursor != w.cursor {
+			if err = w.store.SaveCursor(ctx, w.address, cursor); err != nil {
+				return fmt.Errorf("save cursor: %w", err)
+			}
+			w.cursor = cursor
+		}
+	}
+	return it.Err()
+}
+
+// reportError passes a retried error to OnError and the client logger
+func (w *addressWatcher) reportError(err error) {
+	w.client.log().Warn("address watcher error", slog.String("address", w.address), slog.Any("error", err))
+	if w.options.OnError != nil {
+		w.options.OnError(err)
+	}
+}
+
+// MemoryCursorStore keeps cursors in memory, so progress is lost when the process exits
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore creates an empty in-memory cursor store
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]string)}
+}
+
+// LoadCursor implements CursorStore
+func (s *MemoryCursorStore) LoadCursor(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[key], nil
+}
+
+// SaveCursor implements CursorStore
+func (s *MemoryCursorStore) SaveCursor(_ context.Context, key string, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[key] = cursor
+	return nil
+}
+
+// FileCursorStore keeps cursors in a JSON file, rewritten atomically on every save
+type FileCursorStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCursorStore creates a cursor store backed by the file at path, which is created on
+// the first save
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+// LoadCursor implements CursorStore
+func (s *FileCursorStore) LoadCursor(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return cursors[key], nil
+}
+
+// SaveCursor implements CursorStore
+func (s *FileCursorStore) SaveCursor(_ context.Context, key string, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.read()
+	if err != nil {
+		return err
+	}
+	cursors[key] = cursor
+
+	data, err := json.Marshal(cursors)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// read loads all cursors from the file
+func (s *FileCursorStore) read() (map[string]string, error) {
+	cursors := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("parse cursor file %s: %w", s.path, err)
+	}
+	return cursors, nil
+}
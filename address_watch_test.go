@@ -0,0 +1,177 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watchServer serves a mutable address history and chain tip
+type watchServer struct {
+	mu      sync.Mutex
+	history []*models.AddressTx
+	tip     uint32
+}
+
+func (s *watchServer) add(tip uint32, txs ...*models.AddressTx) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tx := range txs {
+		// A confirmed transaction replaces its unconfirmed entry
+		for i, existing := range s.history {
+			if existing.TransactionID == tx.TransactionID {
+				s.history = append(s.history[:i], s.history[i+1:]...)
+				break
+			}
+		}
+		s.history = append(s.history, tx)
+	}
+	s.tip = tip
+}
+
+func (s *watchServer) client(t *testing.T) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/block_header/tip", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(models.BlockHeader{Height: s.tip, Hash: fmt.Sprintf("hash-%d", s.tip)})
+	})
+	mux.HandleFunc("/v1/address/get/addr/", func(w http.ResponseWriter, r *http.Request) {
+		var fromHeight uint32
+		_, _ = fmt.Sscanf(r.URL.Path, "/v1/address/get/addr/%d", &fromHeight)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		page := make([]*models.AddressTx, 0)
+		for _, tx := range s.history {
+			if tx.BlockHeight == 0 || tx.BlockHeight >= fromHeight {
+				page = append(page, tx)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient))
+	require.NoError(t, err)
+	return client
+}
+
+// collector records delivered transaction IDs and signals each delivery
+type collector struct {
+	mu  sync.Mutex
+	ids []string
+	got chan string
+}
+
+func newCollector() *collector {
+	return &collector{got: make(chan string, 100)}
+}
+
+func (c *collector) handle(tx *models.AddressTx) error {
+	c.mu.Lock()
+	c.ids = append(c.ids, tx.TransactionID)
+	c.mu.Unlock()
+	c.got <- tx.TransactionID
+	return nil
+}
+
+func (c *collector) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d", i+1)
+		}
+	}
+}
+
+func TestWatchAddress(t *testing.T) {
+	t.Run("backfills then follows the chain tip", func(t *testing.T) {
+		server := &watchServer{}
+		server.add(2,
+			&models.AddressTx{TransactionID: "a", BlockHeight: 1},
+			&models.AddressTx{TransactionID: "b", BlockHeight: 2},
+		)
+		client := server.client(t)
+		store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursors.json"))
+		c := newCollector()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- client.WatchAddress(ctx, "addr", 0, c.handle, &WatchAddressOptions{
+				PollInterval: 10 * time.Millisecond,
+				CursorStore:  store,
+			})
+		}()
+		c.wait(t, 2)
+
+		// A mempool transaction is delivered with the next block, and not again once it confirms
+		server.add(3, &models.AddressTx{TransactionID: "m"})
+		c.wait(t, 1)
+		server.add(4, &models.AddressTx{TransactionID: "c", BlockHeight: 4}, &models.AddressTx{TransactionID: "m", BlockHeight: 4, BlockIndex: 1})
+		c.wait(t, 1)
+
+		// Wait for a few more polls to make sure nothing is delivered twice
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+		assert.Equal(t, []string{"a", "b", "m", "c"}, c.ids)
+
+		// Restart from the stored cursor
+		server.add(5, &models.AddressTx{TransactionID: "d", BlockHeight: 5})
+		c = newCollector()
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			done <- client.WatchAddress(ctx, "addr", 0, c.handle, &WatchAddressOptions{
+				PollInterval: 10 * time.Millisecond,
+				CursorStore:  store,
+			})
+		}()
+		c.wait(t, 1)
+		cancel()
+		<-done
+		assert.Equal(t, []string{"d"}, c.ids)
+	})
+
+	t.Run("handler error stops the watcher", func(t *testing.T) {
+		server := &watchServer{}
+		server.add(1, &models.AddressTx{TransactionID: "a", BlockHeight: 1})
+		client := server.client(t)
+		store := NewMemoryCursorStore()
+
+		errStop := errors.New("stop")
+		err := client.WatchAddress(context.Background(), "addr", 0, func(*models.AddressTx) error {
+			return errStop
+		}, &WatchAddressOptions{CursorStore: store})
+		require.ErrorIs(t, err, errStop)
+
+		cursor, err := store.LoadCursor(context.Background(), "addr")
+		require.NoError(t, err)
+		assert.Empty(t, cursor, "failed transaction is not marked as delivered")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		client, err := New()
+		require.NoError(t, err)
+		noop := func(*models.AddressTx) error { return nil }
+		require.Error(t, client.WatchAddress(getNilContext(), "addr", 0, noop, nil))
+		require.Error(t, client.WatchAddress(context.Background(), "", 0, noop, nil))
+		require.Error(t, client.WatchAddress(context.Background(), "addr", 0, nil, nil))
+	})
+}
@@ -3,6 +3,8 @@ package junglebus
 import (
 	"context"
 	"errors"
+
+	"github.com/b-open-io/go-junglebus/models"
 )
 
 // Login will authenticate with the server using username and password
@@ -37,3 +39,11 @@ func (jb *Client) RefreshToken(ctx context.Context) (string, error) {
 	}
 	return jb.transport.RefreshToken(ctx)
 }
+
+// GetUser will get the user the current token belongs to
+func (jb *Client) GetUser(ctx context.Context) (*models.User, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	return jb.transport.GetUser(ctx)
+}
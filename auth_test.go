@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -220,3 +221,37 @@ func TestRefreshToken(t *testing.T) {
 	_, err = client.RefreshToken(context.Background())
 	require.Error(t, err)
 }
+
+func TestGetUser(t *testing.T) {
+	client, err := New()
+	require.NoError(t, err)
+
+	// Test with nil context
+	_, err = client.GetUser(getNilContext())
+	require.Error(t, err)
+	assert.Equal(t, "context cannot be nil", err.Error())
+
+	// Create test server for successful case
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/user/get", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("token"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":       "test-id",
+			"username": "test-user",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err = New(
+		WithHTTPClient(ts.Listener.Addr().String(), http.DefaultClient),
+		WithToken("test-token"),
+		WithSSL(false),
+	)
+	require.NoError(t, err)
+
+	user, err := client.GetUser(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", user.ID)
+	assert.Equal(t, "test-user", user.Username)
+}
@@ -0,0 +1,152 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// BatchResult is the outcome of fetching one ID in a batch
+type BatchResult[T any] struct {
+	ID    string
+	Value T
+	Err   error
+}
+
+// BatchOptions configures a batch fetch
+type BatchOptions[T any] struct {
+	// OnResult switches to streaming mode: each result is passed to OnResult as soon as it is
+	// available and is not kept in the returned results. OnResult is never called concurrently.
+	OnResult func(result BatchResult[T])
+	// InOrder makes OnResult receive results in input order instead of completion order
+	InOrder bool
+}
+
+// BatchResults holds the results of a batch fetch
+type BatchResults[T any] struct {
+	IDs     []string                  // Requested IDs, deduplicated, in input order
+	Results map[string]BatchResult[T] // Result per ID (empty in streaming mode)
+}
+
+// Get returns the value or error for an ID
+func (r *BatchResults[T]) Get(id string) (T, error) {
+	result, ok := r.Results[id]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%s: not in batch", id)
+	}
+	return result.Value, result.Err
+}
+
+// Ordered returns the results in input order
+func (r *BatchResults[T]) Ordered() []BatchResult[T] {
+	ordered := make([]BatchResult[T], 0, len(r.Results))
+	for _, id := range r.IDs {
+		if result, ok := r.Results[id]; ok {
+			ordered = append(ordered, result)
+		}
+	}
+	return ordered
+}
+
+// Err joins the per-ID errors in input order, or returns nil if every ID succeeded
+func (r *BatchResults[T]) Err() error {
+	var errs []error
+	for _, result := range r.Ordered() {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.ID, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetTransactions fetches many transactions concurrently, within the transport's concurrency
+// limit. Duplicate IDs are fetched once. A failed ID is reported in its result and does not
+// fail the batch; the returned error is only set for invalid arguments or a cancelled context.
+func (jb *Client) GetTransactions(ctx context.Context, txIDs []string, options *BatchOptions[*models.Transaction]) (*BatchResults[*models.Transaction], error) {
+	return fetchBatch(ctx, txIDs, options, jb.transport.GetTransaction)
+}
+
+// GetRawTransactions fetches many raw transactions concurrently, like GetTransactions
+func (jb *Client) GetRawTransactions(ctx context.Context, txIDs []string, options *BatchOptions[[]byte]) (*BatchResults[[]byte], error) {
+	return fetchBatch(ctx, txIDs, options, jb.transport.GetRawTransaction)
+}
+
+// GetBeefs fetches the BEEF of many transactions concurrently, like GetTransactions
+func (jb *Client) GetBeefs(ctx context.Context, txIDs []string, options *BatchOptions[[]byte]) (*BatchResults[[]byte], error) {
+	return fetchBatch(ctx, txIDs, options, jb.transport.GetBeef)
+}
+
+// fetchBatch fetches every unique ID with fetch, collecting or streaming the results
+func fetchBatch[T any](ctx context.Context, ids []string, options *BatchOptions[T],
+	fetch func(ctx context.Context, id string) (T, error)) (*BatchResults[T], error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	if options == nil {
+		options = &BatchOptions[T]{}
+	}
+
+	results := &BatchResults[T]{
+		IDs:     make([]string, 0, len(ids)),
+		Results: make(map[string]BatchResult[T]),
+	}
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, errors.New("transaction ID cannot be empty")
+		}
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			results.IDs = append(results.IDs, id)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		pending = make(map[int]BatchResult[T]) // completed results waiting for earlier ones (InOrder)
+		next    int
+	)
+	deliver := func(i int, result BatchResult[T]) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case options.OnResult == nil:
+			results.Results[result.ID] = result
+		case !options.InOrder:
+			options.OnResult(result)
+		default:
+			pending[i] = result
+			for {
+				ready, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				options.OnResult(ready)
+			}
+		}
+	}
+
+	fanOut(ctx, len(results.IDs), func(ctx context.Context, i int) {
+		id := results.IDs[i]
+		value, err := fetch(ctx, id)
+		deliver(i, BatchResult[T]{ID: id, Value: value, Err: err})
+	})
+
+	if err := ctx.Err(); err != nil {
+		if options.OnResult == nil {
+			for _, id := range results.IDs {
+				if _, ok := results.Results[id]; !ok {
+					results.Results[id] = BatchResult[T]{ID: id, Err: err}
+				}
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
@@ -0,0 +1,131 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchClient(t *testing.T, requests *int32) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transaction/get/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		path := strings.TrimPrefix(r.URL.Path, "/v1/transaction/get/")
+		id, bin := strings.CutSuffix(path, "/bin")
+		if strings.HasPrefix(id, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		if id == "slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if bin {
+			_, _ = w.Write([]byte("raw-" + id))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.Transaction{ID: id})
+	})
+	mux.HandleFunc("/v1/transaction/beef/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("beef-" + strings.TrimPrefix(r.URL.Path, "/v1/transaction/beef/")))
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient), WithMaxConcurrentRequests(3))
+	require.NoError(t, err)
+	return client
+}
+
+func TestGetTransactions(t *testing.T) {
+	ctx := context.Background()
+	var requests int32
+	client := newBatchClient(t, &requests)
+
+	t.Run("dedupes and keeps order", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		results, err := client.GetTransactions(ctx, []string{"b", "a", "missing", "b", "c"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+		assert.Equal(t, []string{"b", "a", "missing", "c"}, results.IDs)
+
+		ordered := results.Ordered()
+		require.Len(t, ordered, 4)
+		assert.Equal(t, "b", ordered[0].Value.ID)
+		assert.Equal(t, "c", ordered[3].Value.ID)
+
+		tx, err := results.Get("a")
+		require.NoError(t, err)
+		assert.Equal(t, "a", tx.ID)
+		_, err = results.Get("missing")
+		require.Error(t, err)
+		_, err = results.Get("unknown")
+		require.Error(t, err)
+		assert.ErrorContains(t, results.Err(), "missing")
+	})
+
+	t.Run("raw and beef", func(t *testing.T) {
+		raw, err := client.GetRawTransactions(ctx, []string{"a", "missing"}, nil)
+		require.NoError(t, err)
+		value, err := raw.Get("a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("raw-a"), value)
+		_, err = raw.Get("missing")
+		require.ErrorIs(t, err, transports.ErrNotFound)
+
+		beefs, err := client.GetBeefs(ctx, []string{"a"}, nil)
+		require.NoError(t, err)
+		value, err = beefs.Get("a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("beef-a"), value)
+	})
+
+	t.Run("streams in order", func(t *testing.T) {
+		var ids []string
+		results, err := client.GetRawTransactions(ctx, []string{"slow", "a", "b", "c"}, &BatchOptions[[]byte]{
+			InOrder: true,
+			OnResult: func(result BatchResult[[]byte]) {
+				require.NoError(t, result.Err)
+				ids = append(ids, result.ID)
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results.Results)
+		assert.Equal(t, []string{"slow", "a", "b", "c"}, ids)
+	})
+
+	t.Run("streams as completed", func(t *testing.T) {
+		var ids []string
+		_, err := client.GetRawTransactions(ctx, []string{"slow", "a"}, &BatchOptions[[]byte]{
+			OnResult: func(result BatchResult[[]byte]) {
+				ids = append(ids, result.ID)
+			},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"slow", "a"}, ids)
+	})
+
+	t.Run("validation and cancellation", func(t *testing.T) {
+		_, err := client.GetTransactions(getNilContext(), []string{"a"}, nil)
+		require.Error(t, err)
+		_, err = client.GetTransactions(ctx, []string{""}, nil)
+		require.Error(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		results, err := client.GetTransactions(cancelled, []string{"a", "b"}, nil)
+		require.ErrorIs(t, err, context.Canceled)
+		_, err = results.Get("b")
+		require.Error(t, err)
+	})
+}
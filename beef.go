@@ -0,0 +1,164 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/b-open-io/go-junglebus/internal/beef"
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// MaxBeefTransactions limits the number of transactions BuildBeef collects
+const MaxBeefTransactions = 10000
+
+// ErrBeefTooLarge is returned when BuildBeef would need more than MaxBeefTransactions
+var ErrBeefTooLarge = errors.New("beef: too many unproven ancestors")
+
+// beefTx is a transaction collected for a BEEF package
+type beefTx struct {
+	raw    []byte
+	parsed *rawtx.Tx
+	bump   *beef.Bump // nil if the transaction has no proof
+}
+
+// BuildBeef assembles a BEEF package for a transaction from raw transactions and merkle proofs.
+// It walks the inputs of unproven transactions back to ancestors that have a proof, so it works
+// for mined and mempool transactions when GetBeef does not. Proofs for the same block are merged.
+func (jb *Client) BuildBeef(ctx context.Context, txID string) ([]byte, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	if txID == "" {
+		return nil, errors.New("transaction ID cannot be empty")
+	}
+
+	txs := make(map[string]*beefTx)
+	frontier := []string{txID}
+	for len(frontier) > 0 {
+		if len(txs)+len(frontier) > MaxBeefTransactions {
+			return nil, fmt.Errorf("%w: more than %d", ErrBeefTooLarge, MaxBeefTransactions)
+		}
+
+		entries := make([]*beefTx, len(frontier))
+		errs := make([]error, len(frontier))
+		fanOut(ctx, len(frontier), func(ctx context.Context, i int) {
+			entries[i], errs[i] = jb.beefTransaction(ctx, frontier[i])
+		})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for _, entry := range entries {
+			txs[entry.parsed.ID] = entry
+		}
+		for _, entry := range entries {
+			if entry.bump != nil {
+				continue
+			}
+			for _, in := range entry.parsed.Inputs {
+				if in.IsCoinbase() {
+					return nil, fmt.Errorf("beef: coinbase transaction %s has no proof", entry.parsed.ID)
+				}
+				if _, ok := txs[in.PrevTxID]; !ok {
+					txs[in.PrevTxID] = nil // claimed; filled in by the next level
+					next = append(next, in.PrevTxID)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return encodeBeef(txID, txs)
+}
+
+// beefTransaction fetches a raw transaction and its merkle proof, if it has one
+func (jb *Client) beefTransaction(ctx context.Context, txID string) (*beefTx, error) {
+	raw, err := jb.transport.GetRawTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", txID, err)
+	}
+	parsed, err := rawtx.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", txID, err)
+	}
+	if parsed.ID != txID {
+		return nil, fmt.Errorf("transaction %s: server returned %s", txID, parsed.ID)
+	}
+
+	entry := &beefTx{raw: raw, parsed: parsed}
+	proof, err := jb.transport.GetProof(ctx, txID)
+	if errors.Is(err, transports.ErrNotFound) || (err == nil && len(proof) == 0) {
+		return entry, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("proof %s: %w", txID, err)
+	}
+	if entry.bump, err = beef.ParseBump(proof); err != nil {
+		return nil, fmt.Errorf("proof %s: %w", txID, err)
+	}
+	if !entry.bump.Contains(txID) {
+		return nil, fmt.Errorf("proof %s: %w: transaction not in path", txID, beef.ErrInvalidBump)
+	}
+	return entry, nil
+}
+
+// encodeBeef orders the collected transactions parents first, merges their proofs by block
+// and encodes the package
+func encodeBeef(root string, txs map[string]*beefTx) ([]byte, error) {
+	bumpsByHeight := make(map[uint64]*beef.Bump)
+	for _, entry := range txs {
+		if entry.bump == nil {
+			continue
+		}
+		existing, ok := bumpsByHeight[entry.bump.BlockHeight]
+		if !ok {
+			bumpsByHeight[entry.bump.BlockHeight] = entry.bump
+			continue
+		}
+		if err := existing.Merge(entry.bump); err != nil {
+			return nil, fmt.Errorf("proof %s: %w", entry.parsed.ID, err)
+		}
+	}
+
+	heights := make([]uint64, 0, len(bumpsByHeight))
+	for height := range bumpsByHeight {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	bumps := make([]*beef.Bump, len(heights))
+	bumpIndex := make(map[uint64]int, len(heights))
+	for i, height := range heights {
+		bumps[i] = bumpsByHeight[height]
+		bumpIndex[height] = i
+	}
+
+	// Depth-first, emitting a transaction after the transactions it spends
+	ordered := make([]beef.Tx, 0, len(txs))
+	visited := make(map[string]struct{}, len(txs))
+	var visit func(txID string)
+	visit = func(txID string) {
+		if _, ok := visited[txID]; ok {
+			return
+		}
+		visited[txID] = struct{}{}
+		entry := txs[txID]
+		tx := beef.Tx{Raw: entry.raw, Bump: -1}
+		if entry.bump != nil {
+			tx.Bump = bumpIndex[entry.bump.BlockHeight]
+		} else {
+			for _, in := range entry.parsed.Inputs {
+				visit(in.PrevTxID)
+			}
+		}
+		ordered = append(ordered, tx)
+	}
+	visit(root)
+
+	return beef.Encode(bumps, ordered), nil
+}
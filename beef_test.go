@@ -0,0 +1,135 @@
+package junglebus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/internal/beef"
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildBeefTx serializes a transaction spending the given txid:vout outpoints with two outputs
+func buildBeefTx(inputs ...string) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+	buf.WriteByte(byte(len(inputs)))
+	for _, in := range inputs {
+		txID, vout, _ := strings.Cut(in, ":")
+		hash, _ := hex.DecodeString(txID)
+		for i := len(hash) - 1; i >= 0; i-- {
+			buf.WriteByte(hash[i])
+		}
+		buf.WriteByte(byte(vout[0] - '0'))
+		buf.Write([]byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff})
+	}
+	buf.WriteByte(2)
+	for i := 0; i < 2; i++ {
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(1000))
+		buf.Write([]byte{1, 0x51})
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+// twoTxBump returns a merkle path proving the second transaction of a two transaction block
+func twoTxBump(height byte, sibling, txID string) []byte {
+	leaf := func(offset, flags byte, id string) []byte {
+		hash, _ := hex.DecodeString(id)
+		data := []byte{offset, flags}
+		for i := len(hash) - 1; i >= 0; i-- {
+			data = append(data, hash[i])
+		}
+		return data
+	}
+	data := []byte{height, 1, 2} // block height, tree height, leaves at level 0
+	data = append(data, leaf(0, 0, sibling)...)
+	return append(data, leaf(1, 2, txID)...)
+}
+
+func TestClient_BuildBeef(t *testing.T) {
+	ctx := context.Background()
+	coinbase := strings.Repeat("0", 64) + ":0"
+
+	// p and q are mined in different blocks, c spends p, g spends c and q
+	filler := buildBeefTx(coinbase)
+	p := buildBeefTx(coinbase)
+	q := buildBeefTx(rawtx.TxID(filler) + ":1")
+	c := buildBeefTx(rawtx.TxID(p) + ":0")
+	g := buildBeefTx(rawtx.TxID(c)+":0", rawtx.TxID(q)+":0", rawtx.TxID(c)+":1")
+
+	raws := map[string][]byte{}
+	for _, raw := range [][]byte{p, q, c, g} {
+		raws[rawtx.TxID(raw)] = raw
+	}
+	proofs := map[string][]byte{
+		rawtx.TxID(p): twoTxBump(10, rawtx.TxID(filler), rawtx.TxID(p)),
+		rawtx.TxID(q): twoTxBump(11, rawtx.TxID(filler), rawtx.TxID(q)),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transaction/get/", func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := raws[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/transaction/get/"), "/bin")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(raw)
+	})
+	mux.HandleFunc("/v1/transaction/proof/", func(w http.ResponseWriter, r *http.Request) {
+		proof, ok := proofs[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/transaction/proof/"), "/bin")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(proof)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient))
+	require.NoError(t, err)
+
+	bumpP, err := beef.ParseBump(proofs[rawtx.TxID(p)])
+	require.NoError(t, err)
+	bumpQ, err := beef.ParseBump(proofs[rawtx.TxID(q)])
+	require.NoError(t, err)
+
+	t.Run("unconfirmed chain", func(t *testing.T) {
+		data, err := client.BuildBeef(ctx, rawtx.TxID(g))
+		require.NoError(t, err)
+		// Parents come first, proofs are indexed by block height
+		want := beef.Encode([]*beef.Bump{bumpP, bumpQ}, []beef.Tx{
+			{Raw: p, Bump: 0}, {Raw: c, Bump: -1}, {Raw: q, Bump: 1}, {Raw: g, Bump: -1},
+		})
+		assert.Equal(t, want, data)
+	})
+
+	t.Run("mined transaction", func(t *testing.T) {
+		data, err := client.BuildBeef(ctx, rawtx.TxID(p))
+		require.NoError(t, err)
+		assert.Equal(t, beef.Encode([]*beef.Bump{bumpP}, []beef.Tx{{Raw: p, Bump: 0}}), data)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := client.BuildBeef(getNilContext(), rawtx.TxID(g))
+		require.Error(t, err)
+		_, err = client.BuildBeef(ctx, "")
+		require.Error(t, err)
+		_, err = client.BuildBeef(ctx, strings.Repeat("f", 64))
+		require.Error(t, err)
+
+		// A proof for a different transaction is rejected
+		proofs[rawtx.TxID(c)] = proofs[rawtx.TxID(p)]
+		defer delete(proofs, rawtx.TxID(c))
+		_, err = client.BuildBeef(ctx, rawtx.TxID(c))
+		require.ErrorIs(t, err, beef.ErrInvalidBump)
+	})
+}
@@ -83,7 +83,7 @@ func (m *channelManager) Unsubscribe(name string) error {
 		return nil // Already unsubscribed or never existed
 	}
 
-	err := sub.Unsubscribe()
+	err := m.remove(sub)
 	delete(m.channels, name)
 	return err
 }
@@ -100,7 +100,7 @@ func (m *channelManager) UnsubscribeAll() error {
 
 	var errs []error
 	for name, sub := range m.channels {
-		if err := sub.Unsubscribe(); err != nil {
+		if err := m.remove(sub); err != nil {
 			errs = append(errs, fmt.Errorf("unsubscribe %s: %w", name, err))
 		}
 		delete(m.channels, name)
@@ -143,16 +143,19 @@ func (m *channelManager) Names() []string {
 	return names
 }
 
-// ReplaceSubscription unsubscribes from oldName and creates a new subscription with newName.
-// This is used to update the main channel position on reconnect.
-func (m *channelManager) ReplaceSubscription(oldName, newName string, handler func(e centrifuge.PublicationEvent)) (*centrifuge.Subscription, error) {
+// ReplaceSubscription subscribes to a new channel newName, then unsubscribes from oldName.
+// This is used to move the main channel to a new position. If the new channel cannot be
+// created or subscribed, the old one is kept, unless it has the same name.
+func (m *channelManager) ReplaceSubscription(oldName, newName string, handler func(e centrifuge.PublicationEvent)) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Unsubscribe from old channel if it exists
-	if oldSub, exists := m.channels[oldName]; exists {
-		oldSub.Unsubscribe()
+	oldSub, hasOld := m.channels[oldName]
+	if hasOld && oldName == newName {
+		// The name can only be used again once the old subscription is removed
+		_ = m.remove(oldSub)
 		delete(m.channels, oldName)
+		hasOld = false
 	}
 
 	// Create new subscription
@@ -160,13 +163,30 @@ func (m *channelManager) ReplaceSubscription(oldName, newName string, handler fu
 		Recoverable: false, // Don't recover on reconnect - we handle position ourselves
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create subscription %s: %w", newName, err)
+		return fmt.Errorf("create subscription %s: %w", newName, err)
 	}
 
 	if handler != nil {
 		sub.OnPublication(handler)
 	}
-
+	if err = sub.Subscribe(); err != nil {
+		_ = m.client.RemoveSubscription(sub)
+		return fmt.Errorf("subscribe %s: %w", newName, err)
+	}
 	m.channels[newName] = sub
-	return sub, nil
+
+	if hasOld {
+		_ = m.remove(oldSub)
+		delete(m.channels, oldName)
+	}
+	return nil
+}
+
+// remove unsubscribes from a channel and removes it from the client, so a subscription
+// with the same name can be created again
+func (m *channelManager) remove(sub *centrifuge.Subscription) error {
+	if err := sub.Unsubscribe(); err != nil {
+		return err
+	}
+	return m.client.RemoveSubscription(sub)
 }
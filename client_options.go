@@ -1,6 +1,7 @@
 package junglebus
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/b-open-io/go-junglebus/transports"
@@ -13,6 +14,12 @@ func WithHTTP(serverURL string) ClientOps {
 			transport, _ := transports.NewTransport(
 				transports.WithHTTP(serverURL),
 				transports.WithDebugging(c.debug),
+				transports.WithMetrics(c.metrics),
+				transports.WithLogger(c.logger),
+				transports.WithCircuitBreaker(c.circuitBreaker),
+				transports.WithRateLimiter(c.rateLimiter),
+				transports.WithAdaptiveConcurrency(c.concurrency),
+				transports.WithRequestCoalescing(!c.noCoalescing),
 			)
 			c.transport = transport
 		}
@@ -26,12 +33,41 @@ func WithHTTPClient(serverURL string, httpClient *http.Client) ClientOps {
 			transport, _ := transports.NewTransport(
 				transports.WithHTTPClient(serverURL, httpClient),
 				transports.WithDebugging(c.debug),
+				transports.WithMetrics(c.metrics),
+				transports.WithLogger(c.logger),
+				transports.WithCircuitBreaker(c.circuitBreaker),
+				transports.WithRateLimiter(c.rateLimiter),
+				transports.WithAdaptiveConcurrency(c.concurrency),
+				transports.WithRequestCoalescing(!c.noCoalescing),
 			)
 			c.transport = transport
 		}
 	}
 }
 
+// WithServers will use several servers (e.g. GorillaPool and a self-hosted JungleBus),
+// spreading HTTP requests across the healthy ones according to the policy. Requests and
+// subscriptions fail over to another server when one goes down.
+func WithServers(serverURLs []string, policy transports.ServerPolicy) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			transport, _ := transports.NewTransport(
+				transports.WithServers(serverURLs, policy),
+				transports.WithDebugging(c.debug),
+				transports.WithMetrics(c.metrics),
+				transports.WithLogger(c.logger),
+				transports.WithCircuitBreaker(c.circuitBreaker),
+				transports.WithRateLimiter(c.rateLimiter),
+				transports.WithAdaptiveConcurrency(c.concurrency),
+				transports.WithRequestCoalescing(!c.noCoalescing),
+			)
+			if transport != nil {
+				c.transport = transport
+			}
+		}
+	}
+}
+
 // WithToken will set the token to use in all requests
 func WithToken(token string) ClientOps {
 	return func(c *Client) {
@@ -86,3 +122,107 @@ func WithMaxConcurrentRequests(n int) ClientOps {
 		}
 	}
 }
+
+// WithAdaptiveConcurrency will let the HTTP concurrency limit grow while requests queue up
+// (e.g. during a backfill) and shrink when the server slows down or fails, between
+// config.Min and config.Max. The current limit is reported by ConcurrencyStats.
+func WithAdaptiveConcurrency(config transports.AdaptiveConcurrency) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.concurrency = &config
+			if t, ok := transports.As[transports.ConcurrencyService](c.transport); ok {
+				t.SetAdaptiveConcurrency(&config)
+			}
+		}
+	}
+}
+
+// WithRequestCoalescing will set whether identical GET requests in flight at the same time,
+// e.g. a transaction fetched for both the mempool and main channels, share one request and
+// response (enabled by default). Shared requests are counted by CoalescingStats.
+func WithRequestCoalescing(enabled bool) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.noCoalescing = !enabled
+			if t, ok := transports.As[transports.CoalescingService](c.transport); ok {
+				t.SetCoalescing(enabled)
+			}
+		}
+	}
+}
+
+// WithMetrics will set the metrics recorder for HTTP requests and subscriptions
+func WithMetrics(metrics Metrics) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.metrics = metrics
+			if t, ok := transports.As[transports.MetricsService](c.transport); ok {
+				t.SetMetrics(metrics)
+			}
+		}
+	}
+}
+
+// WithCircuitBreaker will stop sending HTTP requests to a route class (e.g. transaction
+// lookups) that keeps failing, returning transports.ErrCircuitOpen until a trial request
+// succeeds after the open timeout
+func WithCircuitBreaker(config transports.CircuitBreakerConfig) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.circuitBreaker = &config
+			if t, ok := transports.As[transports.CircuitBreakerService](c.transport); ok {
+				t.SetCircuitBreaker(&config)
+			}
+		}
+	}
+}
+
+// WithRateLimiter will limit the rate of HTTP requests, on top of the concurrency limit. The
+// same limiter can be given to several clients to share one requests-per-second quota:
+//
+//	limiter := transports.NewRateLimiter(transports.RateLimit{RequestsPerSecond: 20, Adaptive: true})
+//	a, _ := junglebus.New(junglebus.WithRateLimiter(limiter))
+//	b, _ := junglebus.New(junglebus.WithRateLimiter(limiter))
+func WithRateLimiter(limiter *transports.RateLimiter) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.rateLimiter = limiter
+			if t, ok := transports.As[transports.RateLimitService](c.transport); ok {
+				t.SetRateLimiter(limiter)
+			}
+		}
+	}
+}
+
+// WithTransportWrapper will wrap the current transport, e.g. with an instrumented transport.
+// It must be given after any option that replaces the transport (WithHTTP, WithHTTPClient).
+func WithTransportWrapper(wrapper TransportWrapper) ClientOps {
+	return func(c *Client) {
+		if c != nil && c.transport != nil && wrapper != nil {
+			c.transport = wrapper(c.transport)
+		}
+	}
+}
+
+// WithEventTracer will set the tracer used when processing subscription events
+func WithEventTracer(tracer EventTracer) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.eventTracer = tracer
+		}
+	}
+}
+
+// WithLogger will set the structured logger used by the client and its subscriptions.
+// Tokens, passwords and private keys are redacted. Without a logger the client is silent
+// unless debugging is turned on.
+func WithLogger(logger *slog.Logger) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.logger = logger
+			if t, ok := transports.As[transports.LoggerService](c.transport); ok {
+				t.SetLogger(logger)
+			}
+		}
+	}
+}
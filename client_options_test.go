@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/b-open-io/go-junglebus/transports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -112,3 +113,14 @@ func TestClientOptions_Chaining(t *testing.T) {
 	assert.True(t, client.transport.IsSSL())
 	assert.Equal(t, "v2", client.transport.GetVersion())
 }
+
+func TestWithAdaptiveConcurrency(t *testing.T) {
+	client, err := New(
+		WithAdaptiveConcurrency(transports.AdaptiveConcurrency{Min: 2, Max: 16}),
+		WithHTTP("test-url"),
+		WithMaxConcurrentRequests(32),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, transports.ConcurrencyStats{Limit: 16, Adaptive: true}, client.ConcurrencyStats(),
+		"kept when the transport is replaced, and clamped to the adaptive range")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+)
+
+// runAddress implements: address txs|details <address> [--from-height n]
+func runAddress(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("address: expected subcommand: txs or details")
+	}
+	sub := args[0]
+	flags := c.newFlagSet("address " + sub)
+	fromHeight := flags.Uint("from-height", 0, "only return transactions from this block height")
+	values, err := parseFlags(flags, args[1:], "address")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "txs":
+		txs, err := c.client.GetAddressTransactions(ctx, values[0], uint32(*fromHeight))
+		if err != nil {
+			return err
+		}
+		return c.printJSON(txs)
+	case "details":
+		txs, err := c.client.GetAddressTransactionDetails(ctx, values[0], uint32(*fromHeight))
+		if err != nil {
+			return err
+		}
+		return c.printJSON(txs)
+	default:
+		return usageError("address: unknown subcommand %q", sub)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+// Environment variables used as defaults for the global flags
+const (
+	envServer = "JUNGLEBUS_SERVER"
+	envToken  = "JUNGLEBUS_TOKEN"
+	envDebug  = "JUNGLEBUS_DEBUG"
+)
+
+const usage = `Usage: junglebus [global flags] <command> [subcommand] [flags] [args]
+
+Commands:
+  tx get|raw|beef|proof <txid>        fetch a transaction
+  txo get|spend <txid> <vout>         fetch a transaction output or its spend
+  headers get <block>                 fetch a block header by hash or height
+  headers list <block> [--limit n]    list block headers from a block
+  headers tip                         fetch the chain tip
+  address txs|details <address>       fetch the history of an address
+  user                                fetch the authenticated user
+  subscribe <subscription-id>         stream subscription events as NDJSON
+
+Global flags:
+`
+
+// errUsage is returned for invalid command lines
+var errUsage = errors.New("usage error")
+
+// cli holds the state shared by all commands
+type cli struct {
+	client *junglebus.Client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command runs a command with the remaining arguments
+type command func(ctx context.Context, c *cli, args []string) error
+
+// commands maps command names to their implementation
+var commands = map[string]command{
+	"tx":        runTx,
+	"txo":       runTxo,
+	"headers":   runHeaders,
+	"address":   runAddress,
+	"user":      runUser,
+	"subscribe": runSubscribe,
+}
+
+// run parses the global flags, runs the command and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("junglebus", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	debugDefault, _ := strconv.ParseBool(getenv(envDebug))
+	server := flags.String("server", envOr(getenv, envServer, junglebus.DefaultServer), "JungleBus server URL ($"+envServer+")")
+	token := flags.String("token", getenv(envToken), "API token ($"+envToken+")")
+	debug := flags.Bool("debug", debugDefault, "log requests to stderr ($"+envDebug+")")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitUsage
+	}
+
+	opts := []junglebus.ClientOps{junglebus.WithHTTP(*server)}
+	if *token != "" {
+		opts = append(opts, junglebus.WithToken(*token))
+	}
+	if *debug {
+		opts = append(opts, junglebus.WithLogger(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	}
+	client, err := junglebus.New(opts...)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitError
+	}
+
+	c := &cli{client: client, stdout: stdout, stderr: stderr}
+	return exitCode(stderr, cmd(ctx, c, flags.Args()[1:]))
+}
+
+// exitCode reports the error, if any, and maps it to an exit code
+func exitCode(stderr io.Writer, err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	case errors.Is(err, transports.ErrNotFound):
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitNotFound
+	default:
+		_, _ = fmt.Fprintln(stderr, "error:", err)
+		return exitError
+	}
+}
+
+// usageError returns an error for an invalid command line
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{errUsage}, args...)...)
+}
+
+// envOr returns the environment variable or the fallback if it is empty
+func envOr(getenv func(string) string, key, fallback string) string {
+	if value := getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// printJSON writes a value as indented JSON
+func (c *cli) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors as usage errors
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// parseFlags parses the subcommand flags, which may be given before or after the positional
+// arguments, and checks the number of positional arguments
+func parseFlags(flags *flag.FlagSet, args []string, positional ...string) ([]string, error) {
+	var values []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, usageError("%s: %v", flags.Name(), err)
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		values = append(values, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(values) != len(positional) {
+		return nil, usageError("%s: expected arguments: %v", flags.Name(), positional)
+	}
+	return values, nil
+}
+
+// notFound converts a nil result into ErrNotFound
+func notFound(found bool, what string) error {
+	if !found {
+		return fmt.Errorf("%s: %w", what, transports.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+)
+
+// runHeaders implements: headers get <block> | headers list <block> [--limit n] | headers tip
+func runHeaders(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("headers: expected subcommand: get, list or tip")
+	}
+	sub := args[0]
+	flags := c.newFlagSet("headers " + sub)
+
+	switch sub {
+	case "get":
+		values, err := parseFlags(flags, args[1:], "block")
+		if err != nil {
+			return err
+		}
+		header, err := c.client.GetBlockHeader(ctx, values[0])
+		if err != nil {
+			return err
+		}
+		if err = notFound(header != nil, "block header "+values[0]); err != nil {
+			return err
+		}
+		return c.printJSON(header)
+	case "list":
+		limit := flags.Uint("limit", 10, "maximum number of headers")
+		values, err := parseFlags(flags, args[1:], "block")
+		if err != nil {
+			return err
+		}
+		headers, err := c.client.GetBlockHeaders(ctx, values[0], *limit)
+		if err != nil {
+			return err
+		}
+		return c.printJSON(headers)
+	case "tip":
+		if _, err := parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+		tip, err := c.client.GetChainTip(ctx)
+		if err != nil {
+			return err
+		}
+		return c.printJSON(tip)
+	default:
+		return usageError("headers: unknown subcommand %q", sub)
+	}
+}
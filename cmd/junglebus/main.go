@@ -0,0 +1,35 @@
+// Command junglebus is a command-line client for JungleBus.
+//
+// Usage:
+//
+//	junglebus [global flags] <command> [subcommand] [flags] [args]
+//
+// Commands:
+//
+//	tx get|raw|beef|proof <txid>        fetch a transaction
+//	txo get|spend <txid> <vout>         fetch a transaction output or its spend
+//	headers get <block>                 fetch a block header by hash or height
+//	headers list <block> [--limit n]    list block headers from a block
+//	headers tip                         fetch the chain tip
+//	address txs|details <address>       fetch the history of an address
+//	user                                fetch the authenticated user
+//	subscribe <subscription-id>         stream subscription events as NDJSON
+//
+// Global flags can also be set with the JUNGLEBUS_SERVER, JUNGLEBUS_TOKEN and JUNGLEBUS_DEBUG
+// environment variables. The exit code is 0 on success, 1 on errors, 2 on usage errors and
+// 3 when the requested item was not found.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/internal/centrifugetest"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transaction/get/abcd", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(&models.Transaction{ID: "abcd", BlockHeight: 100})
+	})
+	mux.HandleFunc("/v1/transaction/get/abcd/bin", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte{0xde, 0xad, 0xbe, 0xef})
+	})
+	mux.HandleFunc("/v1/transaction/get/missing/bin", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/v1/transaction/get/broken", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/v1/txo/spend/abcd_1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte{0x01, 0x02})
+	})
+	mux.HandleFunc("/v1/block_header/list/100", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		_ = json.NewEncoder(w).Encode([]*models.BlockHeader{{Height: 100}, {Height: 101}})
+	})
+	mux.HandleFunc("/v1/address/get/1Addr/50", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*models.AddressTx{{TransactionID: "abcd", BlockHeight: 60}})
+	})
+	mux.HandleFunc("/v1/user/get", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "env-token", r.Header.Get("token"))
+		_ = json.NewEncoder(w).Encode(&models.User{Username: "bob", PrivateKey: "secret-key"})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func runTest(t *testing.T, env map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr, func(key string) string {
+		return env[key]
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	ts := newTestServer(t)
+	server := "http://" + ts.Listener.Addr().String()
+	env := map[string]string{envServer: server, envToken: "env-token"}
+
+	t.Run("usage", func(t *testing.T) {
+		code, _, stderr := runTest(t, env)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "Usage: junglebus")
+
+		code, _, _ = runTest(t, env, "unknown")
+		assert.Equal(t, exitUsage, code)
+
+		code, _, _ = runTest(t, env, "tx", "get")
+		assert.Equal(t, exitUsage, code)
+
+		code, _, _ = runTest(t, env, "tx", "nope", "abcd")
+		assert.Equal(t, exitUsage, code)
+
+		code, _, _ = runTest(t, env, "txo", "get", "abcd", "x")
+		assert.Equal(t, exitUsage, code)
+
+		code, _, _ = runTest(t, env, "-help")
+		assert.Equal(t, exitOK, code)
+	})
+
+	t.Run("tx get", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "tx", "get", "abcd")
+		require.Equal(t, exitOK, code)
+		tx := &models.Transaction{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), tx))
+		assert.Equal(t, uint32(100), tx.BlockHeight)
+	})
+
+	t.Run("tx raw", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "tx", "raw", "abcd")
+		require.Equal(t, exitOK, code)
+		assert.Equal(t, "deadbeef\n", stdout)
+
+		code, stdout, _ = runTest(t, env, "tx", "raw", "--format", "bin", "abcd")
+		require.Equal(t, exitOK, code)
+		assert.Equal(t, "\xde\xad\xbe\xef", stdout)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		code, _, stderr := runTest(t, env, "tx", "raw", "missing")
+		assert.Equal(t, exitNotFound, code)
+		assert.Contains(t, stderr, "not found")
+
+		code, _, stderr = runTest(t, env, "tx", "get", "missing")
+		assert.Equal(t, exitNotFound, code, "JSON routes report not found too")
+		assert.Contains(t, stderr, "not found")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		code, _, _ := runTest(t, env, "tx", "get", "broken")
+		assert.Equal(t, exitError, code)
+	})
+
+	t.Run("txo spend", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "txo", "spend", "abcd", "1")
+		require.Equal(t, exitOK, code)
+		assert.Equal(t, "0102\n", stdout)
+	})
+
+	t.Run("headers list with flags after arguments", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "headers", "list", "100", "--limit", "2")
+		require.Equal(t, exitOK, code)
+		var headers []*models.BlockHeader
+		require.NoError(t, json.Unmarshal([]byte(stdout), &headers))
+		assert.Len(t, headers, 2)
+	})
+
+	t.Run("address txs", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "address", "txs", "--from-height", "50", "1Addr")
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"transaction_id": "abcd"`)
+	})
+
+	t.Run("user redacts private key", func(t *testing.T) {
+		code, stdout, _ := runTest(t, env, "user")
+		require.Equal(t, exitOK, code)
+		assert.NotContains(t, stdout, "secret-key")
+
+		code, stdout, _ = runTest(t, env, "user", "--show-private-key")
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "secret-key")
+	})
+
+	t.Run("server flag overrides env", func(t *testing.T) {
+		code, _, _ := runTest(t, map[string]string{envServer: "http://127.0.0.1:1"}, "--server", server, "tx", "get", "abcd")
+		assert.Equal(t, exitOK, code)
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+
+	require.NoError(t, saveCheckpoint(path, checkpoint{SubscriptionID: "sub", Block: 800000, Page: 3}))
+	cp, err = loadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, &checkpoint{SubscriptionID: "sub", Block: 800000, Page: 3}, cp)
+
+	// A checkpoint for another subscription is rejected
+	code, _, stderr := runTest(t, nil, "subscribe", "--checkpoint-file", path, "other-sub")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "belongs to subscription sub")
+}
+
+func TestSubscribe_ReconnectFailed(t *testing.T) {
+	ts := centrifugetest.NewServer(t, func(n int) centrifugetest.Mode {
+		if n == 1 {
+			return centrifugetest.Drop
+		}
+		return centrifugetest.Refuse
+	})
+	env := map[string]string{envServer: "http://" + ts.Listener.Addr().String(), envToken: "token"}
+
+	code, stdout, stderr := runTest(t, env, "subscribe", "--max-reconnect-attempts", "1", "sub")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "subscription reconnect failed")
+	assert.Contains(t, stdout, `"type":"error"`)
+}
+
+func TestEventWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := &eventWriter{encoder: json.NewEncoder(&buf)}
+	writer.writeTx("transaction", &models.TransactionResponse{Id: "abcd", BlockHeight: 5, Transaction: []byte{0x01}})
+	writer.write(&streamEvent{Type: "status", StatusCode: 200, Block: 5})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	event := &streamEvent{}
+	require.NoError(t, json.Unmarshal(lines[0], event))
+	assert.Equal(t, "transaction", event.Type)
+	assert.Equal(t, "01", event.Transaction)
+	assert.False(t, event.Time.IsZero())
+}
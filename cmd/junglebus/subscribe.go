@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// streamEvent is a subscription event written as one line of NDJSON
+type streamEvent struct {
+	Type         string    `json:"type"` // transaction, mempool, status or error
+	Time         time.Time `json:"time"`
+	ID           string    `json:"id,omitempty"`
+	BlockHash    string    `json:"block_hash,omitempty"`
+	BlockHeight  uint32    `json:"block_height,omitempty"`
+	BlockIndex   uint64    `json:"block_index,omitempty"`
+	BlockTime    uint32    `json:"block_time,omitempty"`
+	Transaction  string    `json:"transaction,omitempty"` // hex encoded
+	Merkle       string    `json:"merkle,omitempty"`      // hex encoded
+	StatusCode   uint32    `json:"status_code,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Block        uint32    `json:"block,omitempty"`
+	Transactions uint64    `json:"transactions,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// checkpoint is the position stored in the checkpoint file
+type checkpoint struct {
+	SubscriptionID string `json:"subscription_id"`
+	Block          uint32 `json:"block"`
+	Page           uint64 `json:"page"`
+}
+
+// eventWriter writes NDJSON events; handlers are called from several goroutines
+type eventWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	err     error
+	closed  bool
+	onError func()
+}
+
+// write encodes one event, remembering the first write error
+func (w *eventWriter) write(event *streamEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil || w.closed {
+		return
+	}
+	event.Time = time.Now().UTC()
+	if w.err = w.encoder.Encode(event); w.err != nil && w.onError != nil {
+		w.onError()
+	}
+}
+
+// close stops writing events, which handlers may still send after the subscription ended,
+// and returns the first write error
+func (w *eventWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return w.err
+}
+
+// writeTx writes a transaction or mempool event
+func (w *eventWriter) writeTx(eventType string, tx *models.TransactionResponse) {
+	w.write(&streamEvent{
+		Type:        eventType,
+		ID:          tx.Id,
+		BlockHash:   tx.BlockHash,
+		BlockHeight: tx.BlockHeight,
+		BlockIndex:  tx.BlockIndex,
+		BlockTime:   tx.BlockTime,
+		Transaction: hex.EncodeToString(tx.Transaction),
+		Merkle:      hex.EncodeToString(tx.Merkle),
+	})
+}
+
+// loadCheckpoint reads the checkpoint file, returning nil if it does not exist
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cp := &checkpoint{}
+	if err = json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("read checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// saveCheckpoint atomically writes the checkpoint file
+func saveCheckpoint(path string, cp checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runSubscribe implements: subscribe <subscription-id> [--from n] [--lite] [--mempool] [--checkpoint-file path]
+// [--max-reconnect-attempts n]
+func runSubscribe(ctx context.Context, c *cli, args []string) error {
+	flags := c.newFlagSet("subscribe")
+	fromBlock := flags.Uint64("from", 0, "block height to start from")
+	fromPage := flags.Uint64("page", 0, "page within the start block")
+	lite := flags.Bool("lite", false, "receive transaction IDs and block positions only")
+	mempool := flags.Bool("mempool", false, "also stream mempool transactions")
+	checkpointFile := flags.String("checkpoint-file", "", "file to resume from and record progress to")
+	queueSize := flags.Uint("queue-size", 100000, "size of the event queue")
+	maxReconnects := flags.Int("max-reconnect-attempts", 0, "failed reconnect attempts before giving up (0 retries forever)")
+	values, err := parseFlags(flags, args, "subscription-id")
+	if err != nil {
+		return err
+	}
+	subscriptionID := values[0]
+
+	block, page := uint32(*fromBlock), *fromPage
+	if *checkpointFile != "" {
+		cp, err := loadCheckpoint(*checkpointFile)
+		if err != nil {
+			return err
+		}
+		if cp != nil {
+			if cp.SubscriptionID != "" && cp.SubscriptionID != subscriptionID {
+				return usageError("subscribe: checkpoint %s belongs to subscription %s", *checkpointFile, cp.SubscriptionID)
+			}
+			block, page = cp.Block, cp.Page
+		}
+	}
+
+	// Stop streaming if the output can no longer be written
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	writer := &eventWriter{encoder: json.NewEncoder(c.stdout), onError: cancel}
+	var cpMu sync.Mutex
+	position := checkpoint{SubscriptionID: subscriptionID, Block: block, Page: page}
+	var cpErr error
+	storePosition := func(block uint32, page uint64) {
+		cpMu.Lock()
+		defer cpMu.Unlock()
+		position.Block, position.Page = block, page
+		if *checkpointFile != "" && cpErr == nil {
+			cpErr = saveCheckpoint(*checkpointFile, position)
+		}
+	}
+
+	handler := junglebus.EventHandler{
+		OnTransaction: func(tx *models.TransactionResponse) {
+			writer.writeTx("transaction", tx)
+		},
+		OnStatus: func(status *models.ControlResponse) {
+			writer.write(&streamEvent{
+				Type:         "status",
+				StatusCode:   status.StatusCode,
+				Status:       status.Status,
+				Message:      status.Message,
+				Block:        status.Block,
+				Transactions: status.Transactions,
+			})
+			switch junglebus.StatusCode(status.StatusCode) {
+			case junglebus.SubscriptionBlockDone:
+				storePosition(status.Block+1, 0)
+			case junglebus.SubscriptionPageDone:
+				storePosition(status.Block, status.Transactions+1)
+			}
+		},
+		OnError: func(err error) {
+			writer.write(&streamEvent{Type: "error", Error: err.Error()})
+		},
+	}
+	if *mempool {
+		handler.OnMempool = func(tx *models.TransactionResponse) {
+			writer.writeTx("mempool", tx)
+		}
+	}
+
+	sub, err := c.client.SubscribeWithQueue(ctx, subscriptionID, uint64(block), page, handler, &junglebus.SubscribeOptions{
+		QueueSize:  uint32(*queueSize),
+		LiteMode:   *lite,
+		Connection: junglebus.ConnectionOptions{MaxReconnectAttempts: *maxReconnects},
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-sub.Done():
+	}
+	err = sub.Unsubscribe()
+	writeErr := writer.close()
+	if err != nil {
+		return err
+	}
+	// Set if the subscription ended on its own, e.g. when it could not reconnect
+	if err = sub.Err(); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	cpMu.Lock()
+	defer cpMu.Unlock()
+	return cpErr
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// binaryFormats are the supported output formats for binary data
+var binaryFormats = map[string]bool{"hex": true, "bin": true}
+
+// addFormatFlag adds the --format flag for binary output
+func addFormatFlag(flags *flag.FlagSet) *string {
+	return flags.String("format", "hex", "output format for binary data: hex or bin")
+}
+
+// printBinary writes binary data as hex (with a trailing newline) or as raw bytes
+func (c *cli) printBinary(data []byte, format string) error {
+	if format == "bin" {
+		_, err := c.stdout.Write(data)
+		return err
+	}
+	_, err := fmt.Fprintln(c.stdout, hex.EncodeToString(data))
+	return err
+}
+
+// runTx implements: tx get|raw|beef|proof <txid>
+func runTx(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("tx: expected subcommand: get, raw, beef or proof")
+	}
+	sub := args[0]
+	flags := c.newFlagSet("tx " + sub)
+	format := addFormatFlag(flags)
+	values, err := parseFlags(flags, args[1:], "txid")
+	if err != nil {
+		return err
+	}
+	if !binaryFormats[*format] {
+		return usageError("tx %s: unknown format %q", sub, *format)
+	}
+	txID := values[0]
+
+	var data []byte
+	switch sub {
+	case "get":
+		tx, err := c.client.GetTransaction(ctx, txID)
+		if err != nil {
+			return err
+		}
+		if err = notFound(tx != nil, "transaction "+txID); err != nil {
+			return err
+		}
+		return c.printJSON(tx)
+	case "raw":
+		data, err = c.client.GetRawTransaction(ctx, txID)
+	case "beef":
+		data, err = c.client.GetBeef(ctx, txID)
+	case "proof":
+		data, err = c.client.GetProof(ctx, txID)
+	default:
+		return usageError("tx: unknown subcommand %q", sub)
+	}
+	if err != nil {
+		return err
+	}
+	return c.printBinary(data, *format)
+}
+
+// runTxo implements: txo get|spend <txid> <vout>
+func runTxo(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("txo: expected subcommand: get or spend")
+	}
+	sub := args[0]
+	flags := c.newFlagSet("txo " + sub)
+	format := addFormatFlag(flags)
+	values, err := parseFlags(flags, args[1:], "txid", "vout")
+	if err != nil {
+		return err
+	}
+	if !binaryFormats[*format] {
+		return usageError("txo %s: unknown format %q", sub, *format)
+	}
+	vout, err := strconv.ParseUint(values[1], 10, 32)
+	if err != nil {
+		return usageError("txo %s: invalid vout %q", sub, values[1])
+	}
+
+	var data []byte
+	switch sub {
+	case "get":
+		data, err = c.client.GetTxo(ctx, values[0], uint32(vout))
+	case "spend":
+		data, err = c.client.GetSpend(ctx, values[0], uint32(vout))
+	default:
+		return usageError("txo: unknown subcommand %q", sub)
+	}
+	if err != nil {
+		return err
+	}
+	return c.printBinary(data, *format)
+}
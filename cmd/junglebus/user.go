@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// runUser implements: user [--show-private-key]
+func runUser(ctx context.Context, c *cli, args []string) error {
+	flags := c.newFlagSet("user")
+	showPrivateKey := flags.Bool("show-private-key", false, "include the private key in the output")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	user, err := c.client.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+	if err = notFound(user != nil, "user"); err != nil {
+		return err
+	}
+	if !*showPrivateKey && user.PrivateKey != "" {
+		user.PrivateKey = transports.RedactedValue
+	}
+	return c.printJSON(user)
+}
@@ -0,0 +1,43 @@
+package junglebus
+
+import (
+	"context"
+	"sync"
+)
+
+// maxFanOutWorkers caps the goroutines used by batch requests. The number of requests in
+// flight is still governed by the transport's concurrency limiter.
+const maxFanOutWorkers = 64
+
+// fanOut calls fn for every index in [0, n) from a bounded pool of workers, stopping early
+// (without calling fn) for indexes not yet started when ctx is done
+func fanOut(ctx context.Context, n int, fn func(ctx context.Context, i int)) {
+	workers := maxFanOutWorkers
+	if n < workers {
+		workers = n
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(ctx, i)
+			}
+		}()
+	}
+
+	defer func() {
+		close(next)
+		wg.Wait()
+	}()
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case next <- i:
+		}
+	}
+}
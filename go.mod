@@ -4,14 +4,14 @@ go 1.23
 
 require (
 	github.com/centrifugal/centrifuge-go v0.10.4
+	github.com/centrifugal/protocol v0.14.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/protobuf v1.36.4
 )
 
 require (
-	github.com/centrifugal/protocol v0.14.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/jpillora/backoff v1.0.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
@@ -0,0 +1,160 @@
+// Package graph walks transaction ancestry and spend graphs using the JungleBus transaction
+// and txo endpoints, and exports them to DOT or JSON.
+//
+//	walker := graph.NewWalker(client, graph.WithConcurrency(16))
+//	g, err := walker.Ancestors(ctx, txID, 5)
+//	err = g.WriteDOT(os.Stdout)
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Direction is the direction a graph was walked in
+type Direction string
+
+const (
+	// DirectionAncestors walks inputs backwards to the transactions that created them
+	DirectionAncestors Direction = "ancestors"
+	// DirectionDescendants walks outputs forwards to the transactions that spend them
+	DirectionDescendants Direction = "descendants"
+)
+
+// Outpoint identifies a transaction output
+type Outpoint struct {
+	TxID string `json:"txid"`
+	Vout uint32 `json:"vout"`
+}
+
+// String returns the outpoint as txid_vout, the format used by the txo API
+func (o Outpoint) String() string {
+	return fmt.Sprintf("%s_%d", o.TxID, o.Vout)
+}
+
+// Node is a transaction in the graph
+type Node struct {
+	TxID        string `json:"txid"`
+	Depth       int    `json:"depth"`             // Hops from the root
+	BlockHeight uint32 `json:"block_height"`      // 0 if unconfirmed
+	Inputs      int    `json:"inputs"`            // Number of inputs
+	Outputs     int    `json:"outputs"`           // Number of outputs
+	Stopped     bool   `json:"stopped,omitempty"` // The stop predicate matched, so the node was not expanded
+}
+
+// Edge links the output of one transaction to the transaction that spends it
+type Edge struct {
+	From string `json:"from"` // Transaction that created the output
+	Vout uint32 `json:"vout"` // Output index in From
+	To   string `json:"to"`   // Transaction that spends the output
+}
+
+// Graph is the result of a walk
+type Graph struct {
+	Direction Direction
+	Root      string
+	Nodes     map[string]*Node
+	Edges     []Edge
+}
+
+// newGraph creates an empty graph
+func newGraph(direction Direction, root string) *Graph {
+	return &Graph{
+		Direction: direction,
+		Root:      root,
+		Nodes:     make(map[string]*Node),
+	}
+}
+
+// SortedNodes returns the nodes ordered by depth, then transaction ID
+func (g *Graph) SortedNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].TxID < nodes[j].TxID
+	})
+	return nodes
+}
+
+// sortedEdges returns the edges in a stable order
+func (g *Graph) sortedEdges() []Edge {
+	edges := append([]Edge(nil), g.Edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Vout != b.Vout {
+			return a.Vout < b.Vout
+		}
+		return a.To < b.To
+	})
+	return edges
+}
+
+// MarshalJSON encodes the graph with nodes and edges in a stable order
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Direction Direction `json:"direction"`
+		Root      string    `json:"root"`
+		Nodes     []*Node   `json:"nodes"`
+		Edges     []Edge    `json:"edges"`
+	}{
+		Direction: g.Direction,
+		Root:      g.Root,
+		Nodes:     g.SortedNodes(),
+		Edges:     g.sortedEdges(),
+	})
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Edges point in the direction value flows,
+// from the creating transaction to the spending one.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph transactions {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=monospace];\n")
+	for _, node := range g.SortedNodes() {
+		label := shortID(node.TxID)
+		if node.BlockHeight > 0 {
+			label += fmt.Sprintf("\\nheight %d", node.BlockHeight)
+		} else {
+			label += "\\nunconfirmed"
+		}
+		var styles []string
+		if node.TxID == g.Root {
+			styles = append(styles, "bold")
+		}
+		if node.Stopped {
+			styles = append(styles, "dashed")
+		}
+		attrs := fmt.Sprintf("label=%q", label)
+		if len(styles) > 0 {
+			attrs += fmt.Sprintf(", style=%q", strings.Join(styles, ","))
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", node.TxID, attrs)
+	}
+	for _, edge := range g.sortedEdges() {
+		fmt.Fprintf(&b, "  %q -> %q [label=\"%d\"];\n", edge.From, edge.To, edge.Vout)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shortID abbreviates a transaction ID for labels
+func shortID(txID string) string {
+	if len(txID) <= 16 {
+		return txID
+	}
+	return txID[:8] + "…" + txID[len(txID)-8:]
+}
@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTx serializes a transaction spending the given outpoints with n outputs
+func buildTx(inputs []Outpoint, outputs int) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+	buf.WriteByte(byte(len(inputs)))
+	for _, in := range inputs {
+		hash, _ := hex.DecodeString(in.TxID)
+		for i := len(hash) - 1; i >= 0; i-- {
+			buf.WriteByte(hash[i])
+		}
+		_ = binary.Write(&buf, binary.LittleEndian, in.Vout)
+		buf.WriteByte(0)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	}
+	buf.WriteByte(byte(outputs))
+	for i := 0; i < outputs; i++ {
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(1000))
+		buf.Write([]byte{1, 0x51})
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+// fakeSource serves transactions and spends from memory and counts calls
+type fakeSource struct {
+	mu     sync.Mutex
+	txs    map[string]*models.Transaction
+	spends map[Outpoint]string
+	calls  int
+}
+
+func (f *fakeSource) add(raw []byte, height uint32) string {
+	id := rawtx.TxID(raw)
+	f.txs[id] = &models.Transaction{ID: id, Transaction: raw, BlockHeight: height}
+	tx, _ := rawtx.Parse(raw)
+	for _, in := range tx.Inputs {
+		if !in.IsCoinbase() {
+			f.spends[Outpoint{TxID: in.PrevTxID, Vout: in.PrevVout}] = id
+		}
+	}
+	return id
+}
+
+func (f *fakeSource) GetTransaction(_ context.Context, txID string) (*models.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if tx, ok := f.txs[txID]; ok {
+		return tx, nil
+	}
+	return nil, transports.ErrNotFound
+}
+
+func (f *fakeSource) GetSpend(_ context.Context, txID string, vout uint32) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	spender, ok := f.spends[Outpoint{TxID: txID, Vout: vout}]
+	if !ok {
+		return nil, nil
+	}
+	return hex.DecodeString(spender)
+}
+
+// newTestGraph builds: a (coinbase) -> b spends a:0 -> c spends b:0 and a:1 -> d spends c:0
+func newTestGraph() (*fakeSource, map[string]string) {
+	f := &fakeSource{txs: make(map[string]*models.Transaction), spends: make(map[Outpoint]string)}
+	ids := make(map[string]string)
+	ids["a"] = f.add(buildTx([]Outpoint{{TxID: strings.Repeat("0", 64), Vout: 0xffffffff}}, 2), 1)
+	ids["b"] = f.add(buildTx([]Outpoint{{TxID: ids["a"], Vout: 0}}, 1), 2)
+	ids["c"] = f.add(buildTx([]Outpoint{{TxID: ids["b"], Vout: 0}, {TxID: ids["a"], Vout: 1}}, 1), 3)
+	ids["d"] = f.add(buildTx([]Outpoint{{TxID: ids["c"], Vout: 0}}, 2), 0)
+	return f, ids
+}
+
+func nodeIDs(g *Graph) []string {
+	var ids []string
+	for _, node := range g.SortedNodes() {
+		ids = append(ids, node.TxID)
+	}
+	return ids
+}
+
+func TestWalker_Ancestors(t *testing.T) {
+	ctx := context.Background()
+	source, ids := newTestGraph()
+	walker := NewWalker(source, WithConcurrency(2))
+
+	g, err := walker.Ancestors(ctx, ids["d"], 10)
+	require.NoError(t, err)
+	assert.Len(t, g.Nodes, 4)
+	assert.Equal(t, 0, g.Nodes[ids["d"]].Depth)
+	assert.Equal(t, 2, g.Nodes[ids["a"]].Depth, "a is reached first through c")
+	assert.Equal(t, uint32(3), g.Nodes[ids["c"]].BlockHeight)
+	assert.Len(t, g.Edges, 4)
+	assert.Contains(t, g.Edges, Edge{From: ids["a"], Vout: 1, To: ids["c"]})
+
+	// Limited depth
+	g, err = walker.Ancestors(ctx, ids["d"], 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{ids["d"], ids["c"]}, nodeIDs(g))
+
+	// Cached: walking again makes no requests
+	calls := source.calls
+	_, err = walker.Ancestors(ctx, ids["d"], 10)
+	require.NoError(t, err)
+	assert.Equal(t, calls, source.calls)
+
+	// Stop predicate
+	stopAtB := NewWalker(source, WithStop(func(node *Node, _ *models.Transaction) bool {
+		return node.TxID == ids["b"]
+	}))
+	g, err = stopAtB.Ancestors(ctx, ids["c"], 10)
+	require.NoError(t, err)
+	assert.True(t, g.Nodes[ids["b"]].Stopped)
+	assert.Len(t, g.Nodes, 3, "a is still reached directly from c")
+
+	// Node limit
+	_, err = NewWalker(source, WithMaxNodes(2)).Ancestors(ctx, ids["d"], 10)
+	require.ErrorIs(t, err, ErrTooManyNodes)
+
+	// Missing transaction
+	g, err = NewWalker(source).Ancestors(ctx, strings.Repeat("f", 64), 10)
+	require.ErrorIs(t, err, transports.ErrNotFound)
+	assert.Empty(t, g.Nodes)
+}
+
+func TestWalker_Descendants(t *testing.T) {
+	ctx := context.Background()
+	source, ids := newTestGraph()
+	walker := NewWalker(source)
+
+	g, err := walker.Descendants(ctx, Outpoint{TxID: ids["a"], Vout: 0}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, ids["a"], nodeIDs(g)[0])
+	assert.Len(t, g.Nodes, 4)
+	assert.Equal(t, 3, g.Nodes[ids["d"]].Depth)
+	assert.NotContains(t, g.Edges, Edge{From: ids["a"], Vout: 1, To: ids["c"]}, "only the given output of the root is followed")
+
+	g, err = walker.Descendants(ctx, Outpoint{TxID: ids["a"], Vout: 1}, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{ids["a"], ids["c"]}, nodeIDs(g))
+}
+
+func TestGraph_Export(t *testing.T) {
+	source, ids := newTestGraph()
+	g, err := NewWalker(source).Ancestors(context.Background(), ids["c"], 10)
+	require.NoError(t, err)
+
+	var dot bytes.Buffer
+	require.NoError(t, g.WriteDOT(&dot))
+	assert.True(t, strings.HasPrefix(dot.String(), "digraph transactions {"))
+	assert.Contains(t, dot.String(), `"`+ids["b"]+`" -> "`+ids["c"]+`" [label="0"];`)
+	assert.Contains(t, dot.String(), `style="bold"`)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	var decoded struct {
+		Direction string  `json:"direction"`
+		Root      string  `json:"root"`
+		Nodes     []*Node `json:"nodes"`
+		Edges     []Edge  `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "ancestors", decoded.Direction)
+	assert.Equal(t, ids["c"], decoded.Root)
+	assert.Len(t, decoded.Nodes, 3)
+	assert.Len(t, decoded.Edges, 3)
+	assert.Equal(t, ids["c"], decoded.Nodes[0].TxID)
+}
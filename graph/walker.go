@@ -0,0 +1,339 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+const (
+	// DefaultConcurrency is the number of concurrent lookups made by a walker
+	DefaultConcurrency = 8
+	// DefaultMaxNodes is the largest graph a walker builds before giving up
+	DefaultMaxNodes = 10000
+)
+
+// ErrTooManyNodes is returned when a walk reaches the walker's node limit
+var ErrTooManyNodes = errors.New("graph: too many nodes")
+
+// Source is the part of the JungleBus client used to walk graphs.
+// *junglebus.Client implements it.
+type Source interface {
+	GetTransaction(ctx context.Context, txID string) (*models.Transaction, error)
+	GetSpend(ctx context.Context, txID string, vout uint32) ([]byte, error)
+}
+
+var _ Source = (*junglebus.Client)(nil)
+
+// StopFunc decides whether to stop expanding the graph at a node. The node is kept in the
+// graph but its parents (or spenders) are not visited.
+type StopFunc func(node *Node, tx *models.Transaction) bool
+
+// Option configures a Walker
+type Option func(w *Walker)
+
+// WithConcurrency sets the number of concurrent lookups (DefaultConcurrency by default).
+// Requests are still subject to the transport's concurrency limiter.
+func WithConcurrency(n int) Option {
+	return func(w *Walker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithStop sets a predicate that stops the walk at matching nodes, e.g. at a token's genesis
+func WithStop(stop StopFunc) Option {
+	return func(w *Walker) {
+		w.stop = stop
+	}
+}
+
+// WithMaxNodes limits the size of a graph (DefaultMaxNodes by default)
+func WithMaxNodes(n int) Option {
+	return func(w *Walker) {
+		if n > 0 {
+			w.maxNodes = n
+		}
+	}
+}
+
+// cachedTx is a fetched and parsed transaction
+type cachedTx struct {
+	tx     *models.Transaction
+	parsed *rawtx.Tx
+}
+
+// Walker walks transaction graphs. Transactions and spends are cached across walks, so
+// repeated walks over overlapping graphs only fetch what is new. It is safe for concurrent use.
+type Walker struct {
+	source      Source
+	concurrency int
+	maxNodes    int
+	stop        StopFunc
+
+	mu     sync.Mutex
+	txs    map[string]*cachedTx
+	spends map[Outpoint]string // spending transaction ID of spent outputs
+}
+
+// NewWalker creates a walker
+func NewWalker(source Source, opts ...Option) *Walker {
+	w := &Walker{
+		source:      source,
+		concurrency: DefaultConcurrency,
+		maxNodes:    DefaultMaxNodes,
+		txs:         make(map[string]*cachedTx),
+		spends:      make(map[Outpoint]string),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// ClearCache drops all cached transactions and spends
+func (w *Walker) ClearCache() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.txs = make(map[string]*cachedTx)
+	w.spends = make(map[Outpoint]string)
+}
+
+// Ancestors walks up to depth hops backwards from txID through the transactions whose outputs
+// it spends. On error the partial graph is returned along with the error.
+func (w *Walker) Ancestors(ctx context.Context, txID string, depth int) (*Graph, error) {
+	if txID == "" {
+		return nil, errors.New("transaction ID cannot be empty")
+	}
+	g := newGraph(DirectionAncestors, txID)
+
+	err := w.walk(ctx, g, depth, func(ctx context.Context, level []*cachedTx) ([]string, error) {
+		var next []string
+		for _, entry := range level {
+			for _, in := range entry.parsed.Inputs {
+				if in.IsCoinbase() {
+					continue
+				}
+				g.Edges = append(g.Edges, Edge{From: in.PrevTxID, Vout: in.PrevVout, To: entry.parsed.ID})
+				next = append(next, in.PrevTxID)
+			}
+		}
+		return next, nil
+	})
+	return g, err
+}
+
+// Descendants walks up to depth hops forwards from an output through the transactions that
+// spend it and, recursively, their outputs. On error the partial graph is returned along with
+// the error.
+func (w *Walker) Descendants(ctx context.Context, outpoint Outpoint, depth int) (*Graph, error) {
+	if outpoint.TxID == "" {
+		return nil, errors.New("transaction ID cannot be empty")
+	}
+	g := newGraph(DirectionDescendants, outpoint.TxID)
+
+	root := true
+	err := w.walk(ctx, g, depth, func(ctx context.Context, level []*cachedTx) ([]string, error) {
+		var outpoints []Outpoint
+		for _, entry := range level {
+			if root {
+				// Only the given output of the root is followed
+				outpoints = append(outpoints, outpoint)
+				continue
+			}
+			for vout := range entry.parsed.Outputs {
+				outpoints = append(outpoints, Outpoint{TxID: entry.parsed.ID, Vout: uint32(vout)})
+			}
+		}
+		root = false
+
+		spenders := make([]string, len(outpoints))
+		err := w.forEach(ctx, len(outpoints), func(ctx context.Context, i int) error {
+			var err error
+			spenders[i], err = w.spend(ctx, outpoints[i])
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for i, spender := range spenders {
+			if spender == "" {
+				continue
+			}
+			g.Edges = append(g.Edges, Edge{From: outpoints[i].TxID, Vout: outpoints[i].Vout, To: spender})
+			next = append(next, spender)
+		}
+		return next, nil
+	})
+	return g, err
+}
+
+// walk visits the graph breadth first from its root, fetching each level concurrently.
+// expand returns the transaction IDs linked to a level's expandable nodes.
+func (w *Walker) walk(ctx context.Context, g *Graph, depth int,
+	expand func(ctx context.Context, level []*cachedTx) ([]string, error)) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	frontier := []string{g.Root}
+	visited := map[string]struct{}{g.Root: {}}
+	for level := 0; len(frontier) > 0; level++ {
+		if len(g.Nodes)+len(frontier) > w.maxNodes {
+			return fmt.Errorf("%w: more than %d", ErrTooManyNodes, w.maxNodes)
+		}
+
+		entries := make([]*cachedTx, len(frontier))
+		err := w.forEach(ctx, len(frontier), func(ctx context.Context, i int) error {
+			var err error
+			entries[i], err = w.transaction(ctx, frontier[i])
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		expandable := make([]*cachedTx, 0, len(entries))
+		for _, entry := range entries {
+			node := &Node{
+				TxID:        entry.parsed.ID,
+				Depth:       level,
+				BlockHeight: entry.tx.BlockHeight,
+				Inputs:      len(entry.parsed.Inputs),
+				Outputs:     len(entry.parsed.Outputs),
+			}
+			g.Nodes[node.TxID] = node
+			if w.stop != nil && w.stop(node, entry.tx) {
+				node.Stopped = true
+				continue
+			}
+			if level < depth {
+				expandable = append(expandable, entry)
+			}
+		}
+		if len(expandable) == 0 {
+			return nil
+		}
+
+		linked, err := expand(ctx, expandable)
+		if err != nil {
+			return err
+		}
+		frontier = frontier[:0]
+		for _, txID := range linked {
+			if _, ok := visited[txID]; ok {
+				continue
+			}
+			visited[txID] = struct{}{}
+			frontier = append(frontier, txID)
+		}
+	}
+	return nil
+}
+
+// forEach calls fn for each index in [0, n) with at most w.concurrency calls in flight,
+// returning the first error
+func (w *Walker) forEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, w.concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// transaction returns the cached transaction or fetches and parses it
+func (w *Walker) transaction(ctx context.Context, txID string) (*cachedTx, error) {
+	w.mu.Lock()
+	entry, ok := w.txs[txID]
+	w.mu.Unlock()
+	if ok {
+		return entry, nil
+	}
+
+	tx, err := w.source.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", txID, err)
+	}
+	if tx == nil || len(tx.Transaction) == 0 {
+		return nil, fmt.Errorf("transaction %s: %w", txID, transports.ErrNotFound)
+	}
+	parsed, err := rawtx.Parse(tx.Transaction)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", txID, err)
+	}
+
+	entry = &cachedTx{tx: tx, parsed: parsed}
+	w.mu.Lock()
+	w.txs[txID] = entry
+	w.mu.Unlock()
+	return entry, nil
+}
+
+// spend returns the cached spending transaction ID of an output or looks it up.
+// Unspent outputs are not cached, since they may be spent later.
+func (w *Walker) spend(ctx context.Context, outpoint Outpoint) (string, error) {
+	w.mu.Lock()
+	spender, ok := w.spends[outpoint]
+	w.mu.Unlock()
+	if ok {
+		return spender, nil
+	}
+
+	data, err := w.source.GetSpend(ctx, outpoint.TxID, outpoint.Vout)
+	if errors.Is(err, transports.ErrNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("spend %s: %w", outpoint, err)
+	}
+	spender, spent, err := rawtx.SpendingTxID(data)
+	if err != nil {
+		return "", fmt.Errorf("spend %s: %w", outpoint, err)
+	}
+	if spent {
+		w.mu.Lock()
+		w.spends[outpoint] = spender
+		w.mu.Unlock()
+	}
+	return spender, nil
+}
@@ -1,6 +1,8 @@
 package junglebus
 
 import (
+	"time"
+
 	"github.com/b-open-io/go-junglebus/models"
 )
 
@@ -46,4 +48,31 @@ type EventHandler struct {
 	OnMempool     func(tx *models.TransactionResponse)
 	OnStatus      func(response *models.ControlResponse)
 	OnError       func(err error)
+
+	// OnTransactionEvent and OnMempoolEvent receive the same transactions as OnTransaction and
+	// OnMempool, with methods to load the full transaction, BEEF and proof when needed, which
+	// is handy in lite mode. They can be set instead of or as well as the plain handlers.
+	OnTransactionEvent func(event *TransactionEvent)
+	OnMempoolEvent     func(event *TransactionEvent)
+
+	// OnLagChanged is called when the number of blocks behind the chain tip changes.
+	// It may be called from the chain tip poller as well as the event goroutine.
+	OnLagChanged func(stats SubscriptionStats)
+
+	// OnReconnecting is called before each attempt to re-establish a lost connection, with the
+	// attempt number (from 1), the delay before it and why the connection or last attempt failed.
+	// OnReconnected is called once connected again, with the block and page the subscription
+	// resumes from. The "reconnecting" and "connected" statuses are still passed to OnStatus.
+	OnReconnecting func(attempt int, delay time.Duration, err error)
+	OnReconnected  func(block uint32, page uint64)
+}
+
+// handlesMain reports whether the handler wants block transactions
+func (h *EventHandler) handlesMain() bool {
+	return h.OnTransaction != nil || h.OnTransactionEvent != nil
+}
+
+// handlesMempool reports whether the handler wants mempool transactions
+func (h *EventHandler) handlesMempool() bool {
+	return h.OnMempool != nil || h.OnMempoolEvent != nil
 }
@@ -0,0 +1,311 @@
+// Package beef encodes BEEF transaction packages (BRC-62) and the BSV unified merkle paths
+// (BUMP, BRC-74) they contain.
+package beef
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrInvalidBump is returned when a merkle path cannot be parsed or does not verify
+var ErrInvalidBump = errors.New("invalid merkle path")
+
+// version is the BEEF V1 marker, 0x0100BEEF little endian
+var version = []byte{0x01, 0x00, 0xbe, 0xef}
+
+// Leaf flags
+const (
+	flagDuplicate = 0x01 // The leaf duplicates its sibling and carries no hash
+	flagTxID      = 0x02 // The leaf is a transaction ID the path proves
+)
+
+// Leaf is a hash at one level of a merkle path
+type Leaf struct {
+	Offset uint64
+	Flags  byte
+	Hash   []byte // 32 bytes in internal byte order, nil for duplicates
+}
+
+// Bump is a merkle path proving one or more transactions in a block
+type Bump struct {
+	BlockHeight uint64
+	Levels      [][]Leaf // Leaves per tree level, level 0 holding the transaction IDs
+}
+
+// ParseBump parses a merkle path in BUMP binary format
+func ParseBump(data []byte) (*Bump, error) {
+	r := bytes.NewReader(data)
+	height, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	treeHeight, err := r.ReadByte()
+	if err != nil || treeHeight == 0 || treeHeight > 64 {
+		return nil, fmt.Errorf("%w: tree height", ErrInvalidBump)
+	}
+
+	bump := &Bump{BlockHeight: height, Levels: make([][]Leaf, treeHeight)}
+	for level := range bump.Levels {
+		count, err := readVarInt(r)
+		if err != nil || count > uint64(r.Len()) {
+			return nil, fmt.Errorf("%w: level %d", ErrInvalidBump, level)
+		}
+		for i := uint64(0); i < count; i++ {
+			offset, err := readVarInt(r)
+			if err != nil {
+				return nil, err
+			}
+			flags, err := r.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidBump)
+			}
+			leaf := Leaf{Offset: offset, Flags: flags}
+			if flags&flagDuplicate == 0 {
+				leaf.Hash = make([]byte, 32)
+				if _, err = io.ReadFull(r, leaf.Hash); err != nil {
+					return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidBump)
+				}
+			}
+			bump.Levels[level] = append(bump.Levels[level], leaf)
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidBump, r.Len())
+	}
+	return bump, nil
+}
+
+// Bytes returns the merkle path in BUMP binary format
+func (b *Bump) Bytes() []byte {
+	var buf bytes.Buffer
+	writeVarInt(&buf, b.BlockHeight)
+	buf.WriteByte(byte(len(b.Levels)))
+	for _, level := range b.Levels {
+		writeVarInt(&buf, uint64(len(level)))
+		for _, leaf := range level {
+			writeVarInt(&buf, leaf.Offset)
+			buf.WriteByte(leaf.Flags)
+			if leaf.Flags&flagDuplicate == 0 {
+				buf.Write(leaf.Hash)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// Contains reports whether the path proves the transaction
+func (b *Bump) Contains(txID string) bool {
+	_, ok := b.txOffset(txID)
+	return ok
+}
+
+// Root computes the merkle root from a transaction the path proves
+func (b *Bump) Root(txID string) ([]byte, error) {
+	offset, ok := b.txOffset(txID)
+	if !ok {
+		return nil, fmt.Errorf("%w: transaction %s not in path", ErrInvalidBump, txID)
+	}
+	working := b.leaf(0, offset).Hash
+	for level := range b.Levels {
+		sibling, err := b.hashAt(level, (offset>>level)^1)
+		if err != nil {
+			return nil, err
+		}
+		if sibling == nil {
+			sibling = working
+		}
+		if (offset>>level)&1 == 1 {
+			working = hashPair(sibling, working)
+		} else {
+			working = hashPair(working, sibling)
+		}
+	}
+	return working, nil
+}
+
+// Merge adds the leaves of another path for the same block, so one path proves the
+// transactions of both
+func (b *Bump) Merge(other *Bump) error {
+	if b.BlockHeight != other.BlockHeight || len(b.Levels) != len(other.Levels) {
+		return fmt.Errorf("%w: paths are for different blocks", ErrInvalidBump)
+	}
+	rootA, err := b.anyRoot()
+	if err != nil {
+		return err
+	}
+	rootB, err := other.anyRoot()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(rootA, rootB) {
+		return fmt.Errorf("%w: merkle roots differ", ErrInvalidBump)
+	}
+
+	for level := range b.Levels {
+		for _, leaf := range other.Levels[level] {
+			existing := b.leaf(level, leaf.Offset)
+			if existing == nil {
+				b.Levels[level] = append(b.Levels[level], leaf)
+				continue
+			}
+			existing.Flags |= leaf.Flags & flagTxID
+		}
+		sort.Slice(b.Levels[level], func(i, j int) bool {
+			return b.Levels[level][i].Offset < b.Levels[level][j].Offset
+		})
+	}
+	return nil
+}
+
+// anyRoot computes the merkle root from the first transaction the path proves
+func (b *Bump) anyRoot() ([]byte, error) {
+	for _, leaf := range b.Levels[0] {
+		if leaf.Flags&flagTxID != 0 {
+			return b.Root(reversedHex(leaf.Hash))
+		}
+	}
+	return nil, fmt.Errorf("%w: no transaction in path", ErrInvalidBump)
+}
+
+// txOffset returns the level 0 offset of a transaction the path proves
+func (b *Bump) txOffset(txID string) (uint64, bool) {
+	hash, err := hex.DecodeString(txID)
+	if err != nil || len(hash) != 32 || len(b.Levels) == 0 {
+		return 0, false
+	}
+	reverse(hash)
+	for _, leaf := range b.Levels[0] {
+		if leaf.Flags&flagTxID != 0 && bytes.Equal(leaf.Hash, hash) {
+			return leaf.Offset, true
+		}
+	}
+	return 0, false
+}
+
+// leaf returns the leaf at an offset, or nil if the level does not have it
+func (b *Bump) leaf(level int, offset uint64) *Leaf {
+	for i := range b.Levels[level] {
+		if b.Levels[level][i].Offset == offset {
+			return &b.Levels[level][i]
+		}
+	}
+	return nil
+}
+
+// hashAt returns the hash at a position in the tree, computing it from the level below if
+// the path does not include it. A nil hash means the node duplicates its sibling.
+func (b *Bump) hashAt(level int, offset uint64) ([]byte, error) {
+	if leaf := b.leaf(level, offset); leaf != nil {
+		return leaf.Hash, nil
+	}
+	if level == 0 {
+		return nil, fmt.Errorf("%w: missing hash at level 0 offset %d", ErrInvalidBump, offset)
+	}
+	left, err := b.hashAt(level-1, offset*2)
+	if err != nil {
+		return nil, err
+	}
+	right, err := b.hashAt(level-1, offset*2+1)
+	if err != nil {
+		return nil, err
+	}
+	if right == nil {
+		right = left
+	}
+	return hashPair(left, right), nil
+}
+
+// Tx is a transaction in a BEEF package
+type Tx struct {
+	Raw  []byte
+	Bump int // Index of the merkle path proving the transaction, or -1
+}
+
+// Encode encodes a BEEF V1 package. Transactions must be ordered so that every transaction
+// comes after the transactions it spends.
+func Encode(bumps []*Bump, txs []Tx) []byte {
+	var buf bytes.Buffer
+	buf.Write(version)
+	writeVarInt(&buf, uint64(len(bumps)))
+	for _, bump := range bumps {
+		buf.Write(bump.Bytes())
+	}
+	writeVarInt(&buf, uint64(len(txs)))
+	for _, tx := range txs {
+		buf.Write(tx.Raw)
+		if tx.Bump < 0 {
+			buf.WriteByte(0)
+			continue
+		}
+		buf.WriteByte(1)
+		writeVarInt(&buf, uint64(tx.Bump))
+	}
+	return buf.Bytes()
+}
+
+func hashPair(left, right []byte) []byte {
+	first := sha256.Sum256(append(append(make([]byte, 0, 64), left...), right...))
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+func reversedHex(b []byte) string {
+	reversed := bytes.Clone(b)
+	reverse(reversed)
+	return hex.EncodeToString(reversed)
+}
+
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidBump)
+	}
+	var size int
+	switch prefix {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(prefix), nil
+	}
+	b := make([]byte, 8)
+	if _, err = io.ReadFull(r, b[:size]); err != nil {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidBump)
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+func writeVarInt(buf *bytes.Buffer, v uint64) {
+	b := make([]byte, 9)
+	switch {
+	case v < 0xfd:
+		buf.WriteByte(byte(v))
+	case v <= 0xffff:
+		b[0] = 0xfd
+		binary.LittleEndian.PutUint16(b[1:], uint16(v))
+		buf.Write(b[:3])
+	case v <= 0xffffffff:
+		b[0] = 0xfe
+		binary.LittleEndian.PutUint32(b[1:], uint32(v))
+		buf.Write(b[:5])
+	default:
+		b[0] = 0xff
+		binary.LittleEndian.PutUint64(b[1:], v)
+		buf.Write(b)
+	}
+}
@@ -0,0 +1,104 @@
+package beef
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHash(s string) []byte {
+	h := sha256.Sum256([]byte(s))
+	return h[:]
+}
+
+// threeTxBlock returns paths proving the first and last transaction of a three transaction
+// block, and the block's merkle root
+func threeTxBlock() (first, last *Bump, root []byte) {
+	h0, h1, h2 := testHash("a"), testHash("b"), testHash("c")
+	left, right := hashPair(h0, h1), hashPair(h2, h2)
+	root = hashPair(left, right)
+
+	first = &Bump{BlockHeight: 100, Levels: [][]Leaf{
+		{{Offset: 0, Flags: flagTxID, Hash: h0}, {Offset: 1, Hash: h1}},
+		{{Offset: 1, Hash: right}},
+	}}
+	last = &Bump{BlockHeight: 100, Levels: [][]Leaf{
+		{{Offset: 2, Flags: flagTxID, Hash: h2}, {Offset: 3, Flags: flagDuplicate}},
+		{{Offset: 0, Hash: left}},
+	}}
+	return first, last, root
+}
+
+func TestBump(t *testing.T) {
+	first, last, root := threeTxBlock()
+	firstID, lastID := reversedHex(testHash("a")), reversedHex(testHash("c"))
+
+	t.Run("root", func(t *testing.T) {
+		got, err := first.Root(firstID)
+		require.NoError(t, err)
+		assert.Equal(t, root, got)
+		got, err = last.Root(lastID)
+		require.NoError(t, err)
+		assert.Equal(t, root, got)
+
+		_, err = first.Root(lastID)
+		require.ErrorIs(t, err, ErrInvalidBump)
+		assert.True(t, first.Contains(firstID))
+		assert.False(t, first.Contains(reversedHex(testHash("b"))), "not flagged as a transaction")
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		parsed, err := ParseBump(last.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, last, parsed)
+
+		_, err = ParseBump(last.Bytes()[:10])
+		require.ErrorIs(t, err, ErrInvalidBump)
+		_, err = ParseBump(append(last.Bytes(), 0))
+		require.ErrorIs(t, err, ErrInvalidBump)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		merged, err := ParseBump(first.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, merged.Merge(last))
+		assert.True(t, merged.Contains(firstID))
+		assert.True(t, merged.Contains(lastID))
+		got, err := merged.Root(lastID)
+		require.NoError(t, err)
+		assert.Equal(t, root, got)
+		assert.Len(t, merged.Levels[0], 4)
+
+		other := &Bump{BlockHeight: 100, Levels: [][]Leaf{
+			{{Offset: 0, Flags: flagTxID, Hash: testHash("x")}, {Offset: 1, Hash: testHash("y")}},
+			{{Offset: 1, Hash: testHash("z")}},
+		}}
+		require.ErrorIs(t, merged.Merge(other), ErrInvalidBump)
+		other.BlockHeight = 101
+		require.ErrorIs(t, merged.Merge(other), ErrInvalidBump)
+	})
+}
+
+func TestEncode(t *testing.T) {
+	first, _, _ := threeTxBlock()
+	data := Encode([]*Bump{first}, []Tx{{Raw: []byte{1, 2}, Bump: 0}, {Raw: []byte{3}, Bump: -1}})
+
+	var want bytes.Buffer
+	want.Write([]byte{0x01, 0x00, 0xbe, 0xef, 1})
+	want.Write(first.Bytes())
+	want.Write([]byte{2, 1, 2, 1, 0, 3, 0})
+	assert.Equal(t, want.Bytes(), data)
+}
+
+func TestVarInt(t *testing.T) {
+	for _, v := range []uint64{0, 0xfc, 0xfd, 0xffff, 0x10000, 0xffffffff, 0x100000000} {
+		var buf bytes.Buffer
+		writeVarInt(&buf, v)
+		got, err := readVarInt(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+}
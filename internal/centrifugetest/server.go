@@ -0,0 +1,79 @@
+// Package centrifugetest provides a server speaking enough of the centrifuge protocol for
+// tests to connect and subscribe.
+package centrifugetest
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// Mode is how the server treats a websocket connection
+type Mode int
+
+const (
+	Refuse Mode = iota // reject the websocket upgrade
+	Drop               // connect, then drop the connection
+	Stay               // connect and stay connected
+)
+
+// NewServer starts a server treating each websocket connection, counted from 1, as mode
+// returns. It is closed when the test ends.
+func NewServer(t testing.TB, mode func(n int) Mode) *httptest.Server {
+	var conns int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/connection/websocket" {
+			http.NotFound(w, r)
+			return
+		}
+		m := mode(int(atomic.AddInt32(&conns, 1)))
+		if m == Refuse {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			decoder := protocol.NewProtobufCommandDecoder(data)
+			for {
+				cmd, err := decoder.Decode()
+				if cmd == nil {
+					break
+				}
+				reply := &protocol.Reply{Id: cmd.Id}
+				switch {
+				case cmd.Connect != nil:
+					reply.Connect = &protocol.ConnectResult{Client: "client"}
+				case cmd.Subscribe != nil:
+					reply.Subscribe = &protocol.SubscribeResult{}
+				case cmd.Unsubscribe != nil:
+					reply.Unsubscribe = &protocol.UnsubscribeResult{}
+				}
+				encoded, _ := reply.MarshalVT()
+				_ = conn.WriteMessage(websocket.BinaryMessage, append(binary.AppendUvarint(nil, uint64(len(encoded))), encoded...))
+				if cmd.Connect != nil && m == Drop {
+					time.Sleep(20 * time.Millisecond)
+					return
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
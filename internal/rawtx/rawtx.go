@@ -0,0 +1,175 @@
+// Package rawtx parses the parts of serialized bitcoin transactions used by the client helpers.
+package rawtx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTransaction is returned when a raw transaction cannot be parsed
+var ErrInvalidTransaction = errors.New("invalid raw transaction")
+
+// coinbaseTxID is the previous transaction ID of a coinbase input
+var coinbaseTxID = strings.Repeat("0", 64)
+
+// Input is a parsed transaction input
+type Input struct {
+	PrevTxID string
+	PrevVout uint32
+}
+
+// IsCoinbase reports whether the input is a coinbase input
+func (in Input) IsCoinbase() bool {
+	return in.PrevTxID == coinbaseTxID
+}
+
+// Output is a parsed transaction output
+type Output struct {
+	Satoshis uint64
+	Script   []byte
+}
+
+// Tx is a parsed transaction
+type Tx struct {
+	ID      string
+	Inputs  []Input
+	Outputs []Output
+}
+
+// Parse parses a serialized transaction
+func Parse(raw []byte) (*Tx, error) {
+	r := &reader{buf: raw}
+	tx := &Tx{ID: TxID(raw)}
+
+	r.skip(4) // version
+	inputs := r.varInt()
+	if inputs > uint64(len(raw)) {
+		return nil, fmt.Errorf("%w: %d inputs", ErrInvalidTransaction, inputs)
+	}
+	for i := uint64(0); i < inputs && r.err == nil; i++ {
+		prevHash := r.bytes(32)
+		vout := r.uint32()
+		r.skip(int(r.varInt())) // unlocking script
+		r.skip(4)               // sequence
+		if r.err == nil {
+			tx.Inputs = append(tx.Inputs, Input{PrevTxID: ReversedHex(prevHash), PrevVout: vout})
+		}
+	}
+
+	outputs := r.varInt()
+	if outputs > uint64(len(raw)) {
+		return nil, fmt.Errorf("%w: %d outputs", ErrInvalidTransaction, outputs)
+	}
+	for i := uint64(0); i < outputs && r.err == nil; i++ {
+		satoshis := r.uint64()
+		script := r.bytes(int(r.varInt()))
+		if r.err == nil {
+			tx.Outputs = append(tx.Outputs, Output{Satoshis: satoshis, Script: bytes.Clone(script)})
+		}
+	}
+	r.skip(4) // lock time
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return tx, nil
+}
+
+// reader reads bitcoin wire-format fields, recording the first error
+type reader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf)-r.pos {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidTransaction)
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) varInt() uint64 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	switch b[0] {
+	case 0xfd:
+		if v := r.bytes(2); v != nil {
+			return uint64(binary.LittleEndian.Uint16(v))
+		}
+	case 0xfe:
+		return uint64(r.uint32())
+	case 0xff:
+		return r.uint64()
+	default:
+		return uint64(b[0])
+	}
+	return 0
+}
+
+// TxID returns the transaction ID (double SHA-256, byte-reversed) of a raw transaction
+func TxID(raw []byte) string {
+	first := sha256.Sum256(raw)
+	second := sha256.Sum256(first[:])
+	return ReversedHex(second[:])
+}
+
+// ReversedHex hex-encodes b in reverse byte order
+func ReversedHex(b []byte) string {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return hex.EncodeToString(reversed)
+}
+
+// SpendingTxID decodes a GetSpend response: the spending transaction ID as 32 raw bytes or
+// 64 hex characters, or an empty body if the output is unspent. Raw IDs are in display order,
+// as hex-decoded from the ID, not reversed like the hashes inside a transaction. Only the hex
+// form is trimmed, since any byte of a raw ID can look like whitespace.
+func SpendingTxID(data []byte) (txID string, spent bool, err error) {
+	if len(data) == 32 {
+		return hex.EncodeToString(data), true, nil
+	}
+	data = bytes.TrimSpace(data)
+	switch len(data) {
+	case 0:
+		return "", false, nil
+	case 64:
+		if _, err = hex.DecodeString(string(data)); err == nil {
+			return strings.ToLower(string(data)), true, nil
+		}
+	}
+	return "", false, fmt.Errorf("unexpected spend response of %d bytes", len(data))
+}
@@ -0,0 +1,86 @@
+package rawtx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	prevHash := bytes.Repeat([]byte{0x01}, 31)
+	prevHash = append(prevHash, 0x02)
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+	buf.WriteByte(2)
+	buf.Write(prevHash)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(3))
+	buf.Write([]byte{2, 0xaa, 0xbb})
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.Write(make([]byte, 32))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	buf.WriteByte(1)
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(500))
+	buf.Write([]byte{1, 0x6a})
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	raw := buf.Bytes()
+
+	tx, err := Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, TxID(raw), tx.ID)
+	require.Len(t, tx.Inputs, 2)
+	assert.Equal(t, "02"+strings.Repeat("01", 31), tx.Inputs[0].PrevTxID)
+	assert.Equal(t, uint32(3), tx.Inputs[0].PrevVout)
+	assert.False(t, tx.Inputs[0].IsCoinbase())
+	assert.True(t, tx.Inputs[1].IsCoinbase())
+	require.Len(t, tx.Outputs, 1)
+	assert.Equal(t, uint64(500), tx.Outputs[0].Satoshis)
+	assert.Equal(t, []byte{0x6a}, tx.Outputs[0].Script)
+
+	_, err = Parse(raw[:len(raw)-1])
+	require.ErrorIs(t, err, ErrInvalidTransaction)
+
+	t.Run("huge length", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+		buf.WriteByte(1)
+		buf.Write(prevHash)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+		buf.WriteByte(0xff)
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(0x7fffffffffffffff)) // unlocking script length
+		_, err := Parse(buf.Bytes())
+		require.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+}
+
+func TestSpendingTxID(t *testing.T) {
+	// Not a palindrome, and its raw form starts and ends with bytes that look like whitespace
+	id := "20" + strings.Repeat("0123456789abcdef", 3) + strings.Repeat("ab", 6) + "0a"
+	binaryID, _ := hex.DecodeString(id)
+	require.Len(t, binaryID, 32)
+
+	txID, spent, err := SpendingTxID(nil)
+	require.NoError(t, err)
+	assert.False(t, spent)
+	assert.Empty(t, txID)
+
+	txID, spent, err = SpendingTxID(binaryID)
+	require.NoError(t, err)
+	assert.True(t, spent)
+	assert.Equal(t, id, txID)
+
+	txID, spent, err = SpendingTxID([]byte(strings.ToUpper(id) + "\n"))
+	require.NoError(t, err)
+	assert.True(t, spent)
+	assert.Equal(t, id, txID)
+
+	_, _, err = SpendingTxID([]byte("garbage"))
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@
 package junglebus
 
 import (
+	"log/slog"
 	"sync"
 
 	"github.com/b-open-io/go-junglebus/transports"
@@ -25,6 +26,13 @@ type Client struct {
 	mu               sync.RWMutex
 	subscription     *Subscription
 	debug            bool
+	metrics          Metrics
+	eventTracer      EventTracer
+	logger           *slog.Logger
+	circuitBreaker   *transports.CircuitBreakerConfig
+	rateLimiter      *transports.RateLimiter
+	concurrency      *transports.AdaptiveConcurrency
+	noCoalescing     bool
 }
 
 // New create a new jungle bus client
@@ -58,6 +66,11 @@ func (jb *Client) IsDebug() bool {
 	return jb.debug
 }
 
+// log returns the client logger, which discards everything unless a logger is set or debugging is on
+func (jb *Client) log() *slog.Logger {
+	return transports.ResolveLogger(jb.logger, jb.debug)
+}
+
 // GetTransport returns the current transport service
 func (jb *Client) GetTransport() *transports.TransportService {
 	return &jb.transport
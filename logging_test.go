@@ -0,0 +1,61 @@
+package junglebus
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, err := New(WithLogger(logger), WithHTTP("test-url"))
+	require.NoError(t, err)
+	assert.Equal(t, logger, client.logger)
+
+	sub := &Subscription{
+		SubscriptionID: "test-sub",
+		EventHandler: EventHandler{
+			OnTransaction: func(_ *models.TransactionResponse) {},
+		},
+		client:     client,
+		eventQueue: newEventQueue(10),
+		position:   newPosition(0, 0),
+		options:    &SubscribeOptions{},
+	}
+
+	txData, err := proto.Marshal(&models.TransactionResponse{
+		Id:          "test-tx",
+		BlockHeight: 42,
+		Transaction: []byte("raw-transaction-bytes"),
+	})
+	require.NoError(t, err)
+
+	go sub.handleEvents()
+	sub.addToQueue(&pubEvent{Channel: "main", Data: txData})
+	sub.eventQueue.Close()
+	sub.eventQueue.Wait()
+
+	out := buf.String()
+	assert.Contains(t, out, `"subscription_id":"test-sub"`)
+	assert.Contains(t, out, `"txid":"test-tx"`)
+	assert.Contains(t, out, `"block":42`)
+	assert.Contains(t, out, `"channel":"main"`)
+	assert.NotContains(t, out, "raw-transaction-bytes")
+}
+
+func TestClient_SilentByDefault(t *testing.T) {
+	client, err := New()
+	require.NoError(t, err)
+	assert.False(t, client.log().Enabled(context.Background(), slog.LevelError))
+
+	client.SetDebug(true)
+	assert.True(t, client.log().Enabled(context.Background(), slog.LevelDebug))
+}
@@ -0,0 +1,50 @@
+package junglebus
+
+import (
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// Metrics receives HTTP and subscription measurements from the client.
+// Implementations must be safe for concurrent use; see the metrics/prometheus package for an exporter.
+type Metrics interface {
+	transports.Metrics
+
+	// ObserveEvent is called for every event processed on a subscription channel (control, main, mempool)
+	ObserveEvent(subscriptionID string, channel string)
+	// SetQueueDepth is called with the number of events waiting in a subscription queue
+	SetQueueDepth(subscriptionID string, depth int)
+	// IncReconnects is called every time a subscription re-establishes its connection
+	IncReconnects(subscriptionID string)
+	// SetBlockHeight is called with the current block height of a subscription
+	SetBlockHeight(subscriptionID string, height uint32)
+}
+
+// metrics returns the metrics recorder of the subscription's client, or nil
+func (s *Subscription) metrics() Metrics {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.metrics
+}
+
+// observeEvent reports a processed event to the client metrics recorder, if any
+func (s *Subscription) observeEvent(channel string) {
+	m := s.metrics()
+	if m == nil {
+		return
+	}
+	m.ObserveEvent(s.SubscriptionID, channel)
+	if s.eventQueue != nil {
+		m.SetQueueDepth(s.SubscriptionID, s.eventQueue.Len())
+	}
+	if s.position != nil {
+		m.SetBlockHeight(s.SubscriptionID, s.position.GetBlock())
+	}
+}
+
+// observeReconnect reports a reconnect to the client metrics recorder, if any
+func (s *Subscription) observeReconnect() {
+	if m := s.metrics(); m != nil {
+		m.IncReconnects(s.SubscriptionID)
+	}
+}
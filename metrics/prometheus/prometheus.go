@@ -0,0 +1,219 @@
+// Package prometheus exports go-junglebus client metrics as Prometheus collectors.
+//
+// It lives in its own module so the core client does not depend on Prometheus:
+//
+//	m := prometheus.New(prom.DefaultRegisterer)
+//	client, err := junglebus.New(
+//		junglebus.WithHTTP("https://junglebus.gorillapool.io"),
+//		junglebus.WithMetrics(m),
+//	)
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/transports"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is the metric namespace used when none is given
+const DefaultNamespace = "junglebus"
+
+// Metrics implements junglebus.Metrics using Prometheus collectors
+type Metrics struct {
+	namespace    string
+	buckets      []float64
+	constLabels  prom.Labels
+	registerErrs []error
+
+	requests    *prom.CounterVec
+	latency     *prom.HistogramVec
+	limiterWait prom.Histogram
+	concurrency prom.Gauge
+	coalesced   *prom.CounterVec
+	events      *prom.CounterVec
+	queueDepth  *prom.GaugeVec
+	reconnects  *prom.CounterVec
+	blockHeight *prom.GaugeVec
+}
+
+var (
+	_ junglebus.Metrics             = (*Metrics)(nil)
+	_ transports.ConcurrencyMetrics = (*Metrics)(nil)
+	_ transports.CoalescingMetrics  = (*Metrics)(nil)
+)
+
+// Option configures the Prometheus metrics
+type Option func(m *Metrics)
+
+// WithNamespace overrides the metric namespace (junglebus by default)
+func WithNamespace(namespace string) Option {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithLatencyBuckets overrides the histogram buckets (in seconds) for request latency and limiter wait
+func WithLatencyBuckets(buckets []float64) Option {
+	return func(m *Metrics) {
+		m.buckets = buckets
+	}
+}
+
+// WithConstLabels adds constant labels to every metric, e.g. to tell several clients apart
+func WithConstLabels(labels prom.Labels) Option {
+	return func(m *Metrics) {
+		m.constLabels = labels
+	}
+}
+
+// New creates the collectors and registers them with the given registerer.
+// Registration errors (e.g. duplicate registration) are available from Errors.
+func New(registerer prom.Registerer, opts ...Option) *Metrics {
+	m := &Metrics{
+		namespace: DefaultNamespace,
+		buckets:   prom.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.requests = prom.NewCounterVec(prom.CounterOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "http",
+		Name:        "requests_total",
+		Help:        "Number of HTTP requests made to JungleBus by route, method and status code.",
+		ConstLabels: m.constLabels,
+	}, []string{"route", "method", "status"})
+	m.latency = prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "http",
+		Name:        "request_duration_seconds",
+		Help:        "Latency of HTTP requests made to JungleBus by route and method.",
+		Buckets:     m.buckets,
+		ConstLabels: m.constLabels,
+	}, []string{"route", "method"})
+	m.limiterWait = prom.NewHistogram(prom.HistogramOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "http",
+		Name:        "limiter_wait_seconds",
+		Help:        "Time spent waiting for the request rate and concurrency limiters.",
+		Buckets:     m.buckets,
+		ConstLabels: m.constLabels,
+	})
+	m.concurrency = prom.NewGauge(prom.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "http",
+		Name:        "concurrency_limit",
+		Help:        "Current maximum number of concurrent HTTP requests.",
+		ConstLabels: m.constLabels,
+	})
+	m.coalesced = prom.NewCounterVec(prom.CounterOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "http",
+		Name:        "coalesced_requests_total",
+		Help:        "Number of HTTP requests that shared the response of an identical request in flight.",
+		ConstLabels: m.constLabels,
+	}, []string{"route"})
+	m.events = prom.NewCounterVec(prom.CounterOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "subscription",
+		Name:        "events_total",
+		Help:        "Number of subscription events processed by channel.",
+		ConstLabels: m.constLabels,
+	}, []string{"subscription", "channel"})
+	m.queueDepth = prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "subscription",
+		Name:        "queue_depth",
+		Help:        "Number of events waiting in the subscription queue.",
+		ConstLabels: m.constLabels,
+	}, []string{"subscription"})
+	m.reconnects = prom.NewCounterVec(prom.CounterOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "subscription",
+		Name:        "reconnects_total",
+		Help:        "Number of times the subscription connection was re-established.",
+		ConstLabels: m.constLabels,
+	}, []string{"subscription"})
+	m.blockHeight = prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "subscription",
+		Name:        "block_height",
+		Help:        "Current block height of the subscription.",
+		ConstLabels: m.constLabels,
+	}, []string{"subscription"})
+
+	if registerer != nil {
+		for _, c := range m.Collectors() {
+			if err := registerer.Register(c); err != nil {
+				m.registerErrs = append(m.registerErrs, err)
+			}
+		}
+	}
+
+	return m
+}
+
+// Collectors returns all collectors, for callers that register them manually
+func (m *Metrics) Collectors() []prom.Collector {
+	return []prom.Collector{
+		m.requests,
+		m.latency,
+		m.limiterWait,
+		m.concurrency,
+		m.coalesced,
+		m.events,
+		m.queueDepth,
+		m.reconnects,
+		m.blockHeight,
+	}
+}
+
+// Errors returns the errors encountered while registering the collectors
+func (m *Metrics) Errors() []error {
+	return m.registerErrs
+}
+
+// ObserveRequest records a finished HTTP request
+func (m *Metrics) ObserveRequest(method string, route string, status int, latency time.Duration) {
+	m.requests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.latency.WithLabelValues(route, method).Observe(latency.Seconds())
+}
+
+// ObserveLimiterWait records the time spent waiting for a limiter slot
+func (m *Metrics) ObserveLimiterWait(wait time.Duration) {
+	m.limiterWait.Observe(wait.Seconds())
+}
+
+// SetConcurrencyLimit records the current HTTP concurrency limit
+func (m *Metrics) SetConcurrencyLimit(limit int) {
+	m.concurrency.Set(float64(limit))
+}
+
+// ObserveCoalesced records a request that shared an identical request in flight
+func (m *Metrics) ObserveCoalesced(route string) {
+	m.coalesced.WithLabelValues(route).Inc()
+}
+
+// ObserveEvent records a processed subscription event
+func (m *Metrics) ObserveEvent(subscriptionID string, channel string) {
+	m.events.WithLabelValues(subscriptionID, channel).Inc()
+}
+
+// SetQueueDepth records the current subscription queue depth
+func (m *Metrics) SetQueueDepth(subscriptionID string, depth int) {
+	m.queueDepth.WithLabelValues(subscriptionID).Set(float64(depth))
+}
+
+// IncReconnects records a subscription reconnect
+func (m *Metrics) IncReconnects(subscriptionID string) {
+	m.reconnects.WithLabelValues(subscriptionID).Inc()
+}
+
+// SetBlockHeight records the current subscription block height
+func (m *Metrics) SetBlockHeight(subscriptionID string, height uint32) {
+	m.blockHeight.WithLabelValues(subscriptionID).Set(float64(height))
+}
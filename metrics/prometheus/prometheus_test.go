@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	reg := prom.NewRegistry()
+	m := New(reg)
+	require.Empty(t, m.Errors())
+
+	// Registering twice on the same registry is reported, not panicked
+	m2 := New(reg)
+	assert.Len(t, m2.Errors(), len(m2.Collectors()))
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	reg := prom.NewRegistry()
+	m := New(reg, WithNamespace("test"))
+
+	m.ObserveRequest("GET", "/transaction/get/{txid}", 200, 25*time.Millisecond)
+	m.ObserveRequest("GET", "/transaction/get/{txid}", 200, 50*time.Millisecond)
+	m.ObserveRequest("GET", "/block_header/tip", 0, time.Second)
+	m.ObserveLimiterWait(time.Millisecond)
+	m.SetConcurrencyLimit(12)
+	m.ObserveCoalesced("/transaction/get/{txid}")
+	m.ObserveEvent("sub", "main")
+	m.SetQueueDepth("sub", 42)
+	m.IncReconnects("sub")
+	m.SetBlockHeight("sub", 800000)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("/transaction/get/{txid}", "GET", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("/block_header/tip", "GET", "0")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.events.WithLabelValues("sub", "main")))
+	assert.Equal(t, float64(42), testutil.ToFloat64(m.queueDepth.WithLabelValues("sub")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.reconnects.WithLabelValues("sub")))
+	assert.Equal(t, float64(800000), testutil.ToFloat64(m.blockHeight.WithLabelValues("sub")))
+	assert.Equal(t, float64(12), testutil.ToFloat64(m.concurrency))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.coalesced.WithLabelValues("/transaction/get/{txid}")))
+
+	count, err := testutil.GatherAndCount(reg, "test_http_request_duration_seconds", "test_http_limiter_wait_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
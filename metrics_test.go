@@ -0,0 +1,103 @@
+package junglebus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type testMetrics struct {
+	mu          sync.Mutex
+	requests    int
+	events      map[string]int
+	queueDepth  int
+	reconnects  int
+	blockHeight uint32
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{events: make(map[string]int)}
+}
+
+func (m *testMetrics) ObserveRequest(_ string, _ string, _ int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+}
+
+func (m *testMetrics) ObserveLimiterWait(_ time.Duration) {}
+
+func (m *testMetrics) ObserveEvent(_ string, channel string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[channel]++
+}
+
+func (m *testMetrics) SetQueueDepth(_ string, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueDepth = depth
+}
+
+func (m *testMetrics) IncReconnects(_ string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconnects++
+}
+
+func (m *testMetrics) SetBlockHeight(_ string, height uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blockHeight = height
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := newTestMetrics()
+	client, err := New(WithMetrics(metrics), WithHTTP("test-url"))
+	require.NoError(t, err)
+	assert.Equal(t, metrics, client.metrics)
+}
+
+func TestSubscription_Metrics(t *testing.T) {
+	metrics := newTestMetrics()
+	client, err := New(WithMetrics(metrics))
+	require.NoError(t, err)
+
+	handler, _, _, _ := newTestEventHandler()
+	sub := &Subscription{
+		SubscriptionID: "test-sub",
+		EventHandler:   handler,
+		client:         client,
+		eventQueue:     newEventQueue(100),
+		position:       newPosition(0, 0),
+		options:        &SubscribeOptions{},
+	}
+
+	txData, err := proto.Marshal(&models.TransactionResponse{
+		Id:          "test-tx",
+		BlockHeight: 123,
+		Transaction: []byte("test-tx-data"),
+	})
+	require.NoError(t, err)
+
+	go sub.handleEvents()
+	sub.addToQueue(&pubEvent{Channel: "main", Data: txData})
+	sub.addToQueue(&pubEvent{Channel: "mempool", Data: txData})
+	sub.eventQueue.Close()
+	sub.eventQueue.Wait()
+
+	sub.observeReconnect()
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	assert.Equal(t, 1, metrics.events["main"])
+	assert.Equal(t, 1, metrics.events["mempool"])
+	assert.Equal(t, uint32(123), metrics.blockHeight)
+	assert.Equal(t, 0, metrics.queueDepth)
+	assert.Equal(t, 1, metrics.reconnects)
+}
@@ -1,5 +1,7 @@
 package models
 
+import "log/slog"
+
 // User struct defines a user in the system
 type User struct {
 	ID          string  `json:"id" bson:"id"`
@@ -15,3 +17,18 @@ type User struct {
 	FundUsed    float64 `json:"fund_used" bson:"fund_used"`
 	FundBalance float64 `json:"fund_balance" bson:"fund_balance"`
 }
+
+// LogValue implements slog.LogValuer so the private key is never logged
+func (u *User) LogValue() slog.Value {
+	if u == nil {
+		return slog.Value{}
+	}
+	return slog.GroupValue(
+		slog.String("id", u.ID),
+		slog.String("username", u.Username),
+		slog.String("private_key", "[REDACTED]"),
+		slog.String("public_key", u.PublicKey),
+		slog.String("fund_address", u.FundAddress),
+		slog.Float64("fund_balance", u.FundBalance),
+	)
+}
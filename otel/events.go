@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/models"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// eventSpanKey is the context key holding the span context of the event being processed
+type eventSpanKey struct{}
+
+// eventSpanContext returns the span context of the subscription event being processed, if any
+func eventSpanContext(ctx context.Context) trace.SpanContext {
+	sc, _ := ctx.Value(eventSpanKey{}).(trace.SpanContext)
+	return sc
+}
+
+// EventTracer records a consumer span for every subscription transaction event.
+// Requests made while handling the event, such as fetching the full transaction,
+// are linked to the event span rather than parented by it, so each fetch keeps its own trace.
+type EventTracer struct {
+	tracer trace.Tracer
+}
+
+var _ junglebus.EventTracer = (*EventTracer)(nil)
+
+// NewEventTracer creates an event tracer for junglebus.WithEventTracer
+func NewEventTracer(opts ...Option) *EventTracer {
+	return &EventTracer{
+		tracer: newConfig(opts).tracer(),
+	}
+}
+
+// StartEvent implements junglebus.EventTracer
+func (e *EventTracer) StartEvent(ctx context.Context, subscriptionID string, channel string, tx *models.TransactionResponse) (context.Context, func(err error)) {
+	_, span := e.tracer.Start(ctx, "junglebus.event."+channel,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			AttrSubscriptionID.String(subscriptionID),
+			AttrChannel.String(channel),
+			AttrTxID.String(tx.GetId()),
+			AttrHeight.Int64(int64(tx.GetBlockHeight())),
+		),
+	)
+
+	ctx = context.WithValue(ctx, eventSpanKey{}, span.SpanContext())
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,144 @@
+package otel
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/transports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metric attribute keys
+const (
+	attrRoute  = attribute.Key("junglebus.route")
+	attrMethod = attribute.Key("http.request.method")
+	attrStatus = attribute.Key("junglebus.status")
+)
+
+// Metrics implements junglebus.Metrics with OpenTelemetry instruments.
+// It records the same measurements as the metrics/prometheus package.
+type Metrics struct {
+	requests    metric.Int64Counter
+	latency     metric.Float64Histogram
+	limiterWait metric.Float64Histogram
+	concurrency metric.Int64Gauge
+	coalesced   metric.Int64Counter
+	events      metric.Int64Counter
+	queueDepth  metric.Int64Gauge
+	reconnects  metric.Int64Counter
+	blockHeight metric.Int64Gauge
+}
+
+var (
+	_ junglebus.Metrics             = (*Metrics)(nil)
+	_ transports.ConcurrencyMetrics = (*Metrics)(nil)
+	_ transports.CoalescingMetrics  = (*Metrics)(nil)
+)
+
+// NewMetrics creates the instruments for junglebus.WithMetrics
+func NewMetrics(opts ...Option) (*Metrics, error) {
+	meter := newConfig(opts).meterProvider.Meter(ScopeName)
+	m := &Metrics{}
+
+	var err error
+	if m.requests, err = meter.Int64Counter("junglebus.http.requests",
+		metric.WithDescription("Number of HTTP requests made to JungleBus by route, method and status code."),
+	); err != nil {
+		return nil, err
+	}
+	if m.latency, err = meter.Float64Histogram("junglebus.http.request.duration",
+		metric.WithDescription("Latency of HTTP requests made to JungleBus."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if m.limiterWait, err = meter.Float64Histogram("junglebus.http.limiter.wait",
+		metric.WithDescription("Time spent waiting for the request rate and concurrency limiters."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if m.concurrency, err = meter.Int64Gauge("junglebus.http.concurrency_limit",
+		metric.WithDescription("Current maximum number of concurrent HTTP requests."),
+	); err != nil {
+		return nil, err
+	}
+	if m.coalesced, err = meter.Int64Counter("junglebus.http.coalesced",
+		metric.WithDescription("Number of HTTP requests that shared the response of an identical request in flight."),
+	); err != nil {
+		return nil, err
+	}
+	if m.events, err = meter.Int64Counter("junglebus.subscription.events",
+		metric.WithDescription("Number of subscription events processed by channel."),
+	); err != nil {
+		return nil, err
+	}
+	if m.queueDepth, err = meter.Int64Gauge("junglebus.subscription.queue_depth",
+		metric.WithDescription("Number of events waiting in the subscription queue."),
+	); err != nil {
+		return nil, err
+	}
+	if m.reconnects, err = meter.Int64Counter("junglebus.subscription.reconnects",
+		metric.WithDescription("Number of times the subscription connection was re-established."),
+	); err != nil {
+		return nil, err
+	}
+	if m.blockHeight, err = meter.Int64Gauge("junglebus.subscription.block_height",
+		metric.WithDescription("Current block height of the subscription."),
+	); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveRequest records a finished HTTP request
+func (m *Metrics) ObserveRequest(method string, route string, status int, latency time.Duration) {
+	ctx := context.Background()
+	m.requests.Add(ctx, 1, metric.WithAttributes(
+		attrRoute.String(route), attrMethod.String(method), attrStatus.String(strconv.Itoa(status)),
+	))
+	m.latency.Record(ctx, latency.Seconds(), metric.WithAttributes(
+		attrRoute.String(route), attrMethod.String(method),
+	))
+}
+
+// ObserveLimiterWait records the time spent waiting for a limiter slot
+func (m *Metrics) ObserveLimiterWait(wait time.Duration) {
+	m.limiterWait.Record(context.Background(), wait.Seconds())
+}
+
+// SetConcurrencyLimit records the current HTTP concurrency limit
+func (m *Metrics) SetConcurrencyLimit(limit int) {
+	m.concurrency.Record(context.Background(), int64(limit))
+}
+
+// ObserveCoalesced records a request that shared an identical request in flight
+func (m *Metrics) ObserveCoalesced(route string) {
+	m.coalesced.Add(context.Background(), 1, metric.WithAttributes(attrRoute.String(route)))
+}
+
+// ObserveEvent records a processed subscription event
+func (m *Metrics) ObserveEvent(subscriptionID string, channel string) {
+	m.events.Add(context.Background(), 1, metric.WithAttributes(
+		AttrSubscriptionID.String(subscriptionID), AttrChannel.String(channel),
+	))
+}
+
+// SetQueueDepth records the current subscription queue depth
+func (m *Metrics) SetQueueDepth(subscriptionID string, depth int) {
+	m.queueDepth.Record(context.Background(), int64(depth), metric.WithAttributes(AttrSubscriptionID.String(subscriptionID)))
+}
+
+// IncReconnects records a subscription reconnect
+func (m *Metrics) IncReconnects(subscriptionID string) {
+	m.reconnects.Add(context.Background(), 1, metric.WithAttributes(AttrSubscriptionID.String(subscriptionID)))
+}
+
+// SetBlockHeight records the current subscription block height
+func (m *Metrics) SetBlockHeight(subscriptionID string, height uint32) {
+	m.blockHeight.Record(context.Background(), int64(height), metric.WithAttributes(AttrSubscriptionID.String(subscriptionID)))
+}
@@ -0,0 +1,86 @@
+// Package otel provides OpenTelemetry instrumentation for the go-junglebus client.
+//
+// It lives in its own module so the core client does not depend on OpenTelemetry. All parts are optional:
+//
+//	client, err := junglebus.New(
+//		junglebus.WithHTTPClient("https://junglebus.gorillapool.io", &http.Client{
+//			Transport: otel.NewRoundTripper(nil), // propagate trace context to the server
+//		}),
+//		junglebus.WithTransportWrapper(otel.Wrap()), // span per API call
+//		junglebus.WithEventTracer(otel.NewEventTracer()), // span per subscription event
+//		junglebus.WithMetrics(metrics), // from otel.NewMetrics()
+//	)
+package otel
+
+import (
+	gotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for tracers and meters
+const ScopeName = "github.com/b-open-io/go-junglebus/otel"
+
+// Attribute keys set on spans and metrics
+const (
+	AttrAddress        = attribute.Key("junglebus.address")
+	AttrBlock          = attribute.Key("junglebus.block")
+	AttrChannel        = attribute.Key("junglebus.channel")
+	AttrCount          = attribute.Key("junglebus.count")
+	AttrHeight         = attribute.Key("junglebus.height")
+	AttrLimit          = attribute.Key("junglebus.limit")
+	AttrSubscriptionID = attribute.Key("junglebus.subscription_id")
+	AttrTxID           = attribute.Key("junglebus.txid")
+	AttrVout           = attribute.Key("junglebus.vout")
+)
+
+// config holds the providers used by all instrumentation in this package
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures the instrumentation
+type Option func(c *config)
+
+// WithTracerProvider sets the tracer provider (the global provider by default)
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider (the global provider by default)
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to inject trace context (the global propagator by default)
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// newConfig applies the options over the global defaults
+func newConfig(opts []Option) *config {
+	c := &config{
+		tracerProvider: gotel.GetTracerProvider(),
+		meterProvider:  gotel.GetMeterProvider(),
+		propagator:     gotel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// tracer returns the package tracer from the configured provider
+func (c *config) tracer() trace.Tracer {
+	return c.tracerProvider.Tracer(ScopeName)
+}
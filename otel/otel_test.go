@@ -0,0 +1,166 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestServer(t *testing.T, traceparents chan<- string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transaction/get/test-tx", func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		_ = json.NewEncoder(w).Encode(&models.Transaction{ID: "test-tx", BlockHeight: 100})
+	})
+	mux.HandleFunc("/v1/transaction/get/missing", func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func attrValue(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	opts := []Option{WithTracerProvider(provider), WithPropagator(propagation.TraceContext{})}
+
+	traceparents := make(chan string, 2)
+	ts := newTestServer(t, traceparents)
+
+	client, err := junglebus.New(
+		junglebus.WithHTTPClient("http://"+ts.Listener.Addr().String(), &http.Client{
+			Transport: NewRoundTripper(nil, opts...),
+		}),
+		junglebus.WithTransportWrapper(Wrap(opts...)),
+	)
+	require.NoError(t, err)
+
+	tx, err := client.GetTransaction(context.Background(), "test-tx")
+	require.NoError(t, err)
+	assert.Equal(t, "test-tx", tx.ID)
+
+	_, err = client.GetTransaction(context.Background(), "missing")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	ok := spans[0]
+	assert.Equal(t, "junglebus.GetTransaction", ok.Name())
+	assert.Equal(t, "test-tx", attrValue(ok.Attributes(), AttrTxID).AsString())
+	assert.Equal(t, int64(100), attrValue(ok.Attributes(), AttrHeight).AsInt64())
+	assert.Equal(t, int64(http.StatusOK), attrValue(ok.Attributes(), attrHTTPStatusCode).AsInt64())
+	assert.Equal(t, codes.Ok, ok.Status().Code)
+	assert.Contains(t, <-traceparents, ok.SpanContext().TraceID().String())
+
+	failed := spans[1]
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, int64(http.StatusNotFound), attrValue(failed.Attributes(), attrHTTPStatusCode).AsInt64())
+	assert.Contains(t, <-traceparents, failed.SpanContext().TraceID().String())
+
+	// Optional features of the wrapped transport are still reachable
+	assert.Equal(t, transports.DefaultMaxConcurrentRequests, client.ConcurrencyStats().Limit)
+}
+
+func TestEventTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	traceparents := make(chan string, 1)
+	ts := newTestServer(t, traceparents)
+
+	client, err := junglebus.New(
+		junglebus.WithHTTPClient("http://"+ts.Listener.Addr().String(), http.DefaultClient),
+		junglebus.WithTransportWrapper(Wrap(WithTracerProvider(provider))),
+	)
+	require.NoError(t, err)
+
+	tracer := NewEventTracer(WithTracerProvider(provider))
+	ctx, end := tracer.StartEvent(context.Background(), "sub", "main", &models.TransactionResponse{
+		Id:          "test-tx",
+		BlockHeight: 100,
+	})
+	_, err = client.GetTransaction(ctx, "test-tx")
+	require.NoError(t, err)
+	end(errors.New("handler failed"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	fetch, event := spans[0], spans[1]
+
+	assert.Equal(t, "junglebus.event.main", event.Name())
+	assert.Equal(t, "sub", attrValue(event.Attributes(), AttrSubscriptionID).AsString())
+	assert.Equal(t, codes.Error, event.Status().Code)
+
+	require.Len(t, fetch.Links(), 1)
+	assert.Equal(t, event.SpanContext(), fetch.Links()[0].SpanContext)
+	assert.NotEqual(t, event.SpanContext().TraceID(), fetch.SpanContext().TraceID())
+}
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := NewMetrics(WithMeterProvider(provider))
+	require.NoError(t, err)
+
+	m.ObserveRequest("GET", "/block_header/tip", 200, 10*time.Millisecond)
+	m.ObserveLimiterWait(time.Millisecond)
+	m.SetConcurrencyLimit(12)
+	m.ObserveCoalesced("/transaction/get/{txid}")
+	m.ObserveEvent("sub", "main")
+	m.ObserveEvent("sub", "main")
+	m.SetQueueDepth("sub", 5)
+	m.IncReconnects("sub")
+	m.SetBlockHeight("sub", 800000)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	found := make(map[string]metricdata.Aggregation)
+	for _, metric := range rm.ScopeMetrics[0].Metrics {
+		found[metric.Name] = metric.Data
+	}
+	assert.Len(t, found, 9)
+
+	events := found["junglebus.subscription.events"].(metricdata.Sum[int64])
+	require.Len(t, events.DataPoints, 1)
+	assert.Equal(t, int64(2), events.DataPoints[0].Value)
+
+	limit := found["junglebus.http.concurrency_limit"].(metricdata.Gauge[int64])
+	require.Len(t, limit.DataPoints, 1)
+	assert.Equal(t, int64(12), limit.DataPoints[0].Value)
+
+	height := found["junglebus.subscription.block_height"].(metricdata.Gauge[int64])
+	require.Len(t, height.DataPoints, 1)
+	assert.Equal(t, int64(800000), height.DataPoints[0].Value)
+}
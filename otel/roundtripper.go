@@ -0,0 +1,46 @@
+package otel
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attrHTTPStatusCode is the semantic convention key for the response status code
+const attrHTTPStatusCode = attribute.Key("http.response.status_code")
+
+// roundTripper injects trace context headers into outgoing requests
+type roundTripper struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+// NewRoundTripper returns an http.RoundTripper that propagates the trace context of each request
+// (e.g. traceparent) to the server and records the response status code on the active span.
+// If base is nil, http.DefaultTransport is used.
+func NewRoundTripper(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &roundTripper{
+		base:       base,
+		propagator: newConfig(opts).propagator,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// RoundTrippers must not modify the given request
+	req = req.Clone(ctx)
+	r.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := r.base.RoundTrip(req)
+	if resp != nil {
+		trace.SpanFromContext(ctx).SetAttributes(attrHTTPStatusCode.Int(resp.StatusCode))
+	}
+	return resp, err
+}
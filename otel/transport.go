@@ -0,0 +1,233 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport wraps a transport service and records a span around every API call.
+// Calls made while handling a traced subscription event are linked to the event span.
+type Transport struct {
+	transports.TransportService
+	tracer trace.Tracer
+}
+
+// NewTransport wraps the given transport service
+func NewTransport(next transports.TransportService, opts ...Option) *Transport {
+	return &Transport{
+		TransportService: next,
+		tracer:           newConfig(opts).tracer(),
+	}
+}
+
+// Wrap returns a wrapper for junglebus.WithTransportWrapper
+func Wrap(opts ...Option) junglebus.TransportWrapper {
+	return func(next transports.TransportService) transports.TransportService {
+		return NewTransport(next, opts...)
+	}
+}
+
+// Unwrap returns the wrapped transport service, so its optional features can be found with
+// transports.As
+func (t *Transport) Unwrap() transports.TransportService {
+	return t.TransportService
+}
+
+// traced runs fn inside a client span named after the API call
+func traced[T any](ctx context.Context, t *Transport, name string, attrs []attribute.KeyValue, fn func(ctx context.Context) (T, error)) (T, error) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	}
+	if sc := eventSpanContext(ctx); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	ctx, span := t.tracer.Start(ctx, "junglebus."+name, opts...)
+	defer span.End()
+
+	result, err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return result, err
+}
+
+// GetAddressTransactions traces the wrapped call
+func (t *Transport) GetAddressTransactions(ctx context.Context, address string, fromHeight uint32) ([]*models.AddressTx, error) {
+	attrs := []attribute.KeyValue{AttrAddress.String(address), AttrHeight.Int64(int64(fromHeight))}
+	return traced(ctx, t, "GetAddressTransactions", attrs, func(ctx context.Context) ([]*models.AddressTx, error) {
+		txs, err := t.TransportService.GetAddressTransactions(ctx, address, fromHeight)
+		trace.SpanFromContext(ctx).SetAttributes(AttrCount.Int(len(txs)))
+		return txs, err
+	})
+}
+
+// GetAddressTransactionDetails traces the wrapped call
+func (t *Transport) GetAddressTransactionDetails(ctx context.Context, address string, fromHeight uint32) ([]*models.Transaction, error) {
+	attrs := []attribute.KeyValue{AttrAddress.String(address), AttrHeight.Int64(int64(fromHeight))}
+	return traced(ctx, t, "GetAddressTransactionDetails", attrs, func(ctx context.Context) ([]*models.Transaction, error) {
+		txs, err := t.TransportService.GetAddressTransactionDetails(ctx, address, fromHeight)
+		trace.SpanFromContext(ctx).SetAttributes(AttrCount.Int(len(txs)))
+		return txs, err
+	})
+}
+
+// StreamAddressTransactions traces opening the stream
+func (t *Transport) StreamAddressTransactions(ctx context.Context, address string, fromHeight uint32, limit uint) (*transports.ArrayReader[models.AddressTx], error) {
+	attrs := []attribute.KeyValue{AttrAddress.String(address), AttrHeight.Int64(int64(fromHeight))}
+	return traced(ctx, t, "StreamAddressTransactions", attrs, func(ctx context.Context) (*transports.ArrayReader[models.AddressTx], error) {
+		streams, ok := transports.As[transports.StreamService](t.TransportService)
+		if !ok {
+			return nil, transports.ErrNotSupported
+		}
+		return streams.StreamAddressTransactions(ctx, address, fromHeight, limit)
+	})
+}
+
+// StreamAddressTransactionDetails traces opening the stream
+func (t *Transport) StreamAddressTransactionDetails(ctx context.Context, address string, fromHeight uint32, limit uint) (*transports.ArrayReader[models.Transaction], error) {
+	attrs := []attribute.KeyValue{AttrAddress.String(address), AttrHeight.Int64(int64(fromHeight))}
+	return traced(ctx, t, "StreamAddressTransactionDetails", attrs, func(ctx context.Context) (*transports.ArrayReader[models.Transaction], error) {
+		streams, ok := transports.As[transports.StreamService](t.TransportService)
+		if !ok {
+			return nil, transports.ErrNotSupported
+		}
+		return streams.StreamAddressTransactionDetails(ctx, address, fromHeight, limit)
+	})
+}
+
+// GetBlockHeader traces the wrapped call
+func (t *Transport) GetBlockHeader(ctx context.Context, block string) (*models.BlockHeader, error) {
+	return traced(ctx, t, "GetBlockHeader", []attribute.KeyValue{AttrBlock.String(block)}, func(ctx context.Context) (*models.BlockHeader, error) {
+		return t.TransportService.GetBlockHeader(ctx, block)
+	})
+}
+
+// GetBlockHeaders traces the wrapped call
+func (t *Transport) GetBlockHeaders(ctx context.Context, fromBlock string, limit uint) ([]*models.BlockHeader, error) {
+	attrs := []attribute.KeyValue{AttrBlock.String(fromBlock), AttrLimit.Int64(int64(limit))}
+	return traced(ctx, t, "GetBlockHeaders", attrs, func(ctx context.Context) ([]*models.BlockHeader, error) {
+		headers, err := t.TransportService.GetBlockHeaders(ctx, fromBlock, limit)
+		trace.SpanFromContext(ctx).SetAttributes(AttrCount.Int(len(headers)))
+		return headers, err
+	})
+}
+
+// GetChainTip traces the wrapped call
+func (t *Transport) GetChainTip(ctx context.Context) (*models.BlockHeader, error) {
+	return traced(ctx, t, "GetChainTip", nil, func(ctx context.Context) (*models.BlockHeader, error) {
+		tip, err := t.TransportService.GetChainTip(ctx)
+		if tip != nil {
+			trace.SpanFromContext(ctx).SetAttributes(AttrHeight.Int64(int64(tip.Height)))
+		}
+		return tip, err
+	})
+}
+
+// GetTransaction traces the wrapped call
+func (t *Transport) GetTransaction(ctx context.Context, txID string) (*models.Transaction, error) {
+	return traced(ctx, t, "GetTransaction", []attribute.KeyValue{AttrTxID.String(txID)}, func(ctx context.Context) (*models.Transaction, error) {
+		tx, err := t.TransportService.GetTransaction(ctx, txID)
+		if tx != nil {
+			trace.SpanFromContext(ctx).SetAttributes(AttrHeight.Int64(int64(tx.BlockHeight)))
+		}
+		return tx, err
+	})
+}
+
+// GetRawTransaction traces the wrapped call
+func (t *Transport) GetRawTransaction(ctx context.Context, txID string) ([]byte, error) {
+	return traced(ctx, t, "GetRawTransaction", []attribute.KeyValue{AttrTxID.String(txID)}, func(ctx context.Context) ([]byte, error) {
+		return t.TransportService.GetRawTransaction(ctx, txID)
+	})
+}
+
+// GetBeef traces the wrapped call
+func (t *Transport) GetBeef(ctx context.Context, txID string) ([]byte, error) {
+	return traced(ctx, t, "GetBeef", []attribute.KeyValue{AttrTxID.String(txID)}, func(ctx context.Context) ([]byte, error) {
+		return t.TransportService.GetBeef(ctx, txID)
+	})
+}
+
+// GetProof traces the wrapped call
+func (t *Transport) GetProof(ctx context.Context, txID string) ([]byte, error) {
+	return traced(ctx, t, "GetProof", []attribute.KeyValue{AttrTxID.String(txID)}, func(ctx context.Context) ([]byte, error) {
+		return t.TransportService.GetProof(ctx, txID)
+	})
+}
+
+// GetFromBlock traces the wrapped call
+func (t *Transport) GetFromBlock(ctx context.Context, subscriptionID string, height uint32, lastIdx uint64) ([]*models.Transaction, error) {
+	attrs := []attribute.KeyValue{AttrSubscriptionID.String(subscriptionID), AttrHeight.Int64(int64(height))}
+	return traced(ctx, t, "GetFromBlock", attrs, func(ctx context.Context) ([]*models.Transaction, error) {
+		txs, err := t.TransportService.GetFromBlock(ctx, subscriptionID, height, lastIdx)
+		trace.SpanFromContext(ctx).SetAttributes(AttrCount.Int(len(txs)))
+		return txs, err
+	})
+}
+
+// GetLiteFromBlock traces the wrapped call
+func (t *Transport) GetLiteFromBlock(ctx context.Context, subscriptionID string, height uint32, lastIdx uint64) ([]*models.TransactionResponse, error) {
+	attrs := []attribute.KeyValue{AttrSubscriptionID.String(subscriptionID), AttrHeight.Int64(int64(height))}
+	return traced(ctx, t, "GetLiteFromBlock", attrs, func(ctx context.Context) ([]*models.TransactionResponse, error) {
+		txs, err := t.TransportService.GetLiteFromBlock(ctx, subscriptionID, height, lastIdx)
+		trace.SpanFromContext(ctx).SetAttributes(AttrCount.Int(len(txs)))
+		return txs, err
+	})
+}
+
+// GetTxo traces the wrapped call
+func (t *Transport) GetTxo(ctx context.Context, txID string, vout uint32) ([]byte, error) {
+	attrs := []attribute.KeyValue{AttrTxID.String(txID), AttrVout.Int64(int64(vout))}
+	return traced(ctx, t, "GetTxo", attrs, func(ctx context.Context) ([]byte, error) {
+		return t.TransportService.GetTxo(ctx, txID, vout)
+	})
+}
+
+// GetSpend traces the wrapped call
+func (t *Transport) GetSpend(ctx context.Context, txID string, vout uint32) ([]byte, error) {
+	attrs := []attribute.KeyValue{AttrTxID.String(txID), AttrVout.Int64(int64(vout))}
+	return traced(ctx, t, "GetSpend", attrs, func(ctx context.Context) ([]byte, error) {
+		return t.TransportService.GetSpend(ctx, txID, vout)
+	})
+}
+
+// Login traces the wrapped call
+func (t *Transport) Login(ctx context.Context, username string, password string) error {
+	_, err := traced(ctx, t, "Login", nil, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, t.TransportService.Login(ctx, username, password)
+	})
+	return err
+}
+
+// GetSubscriptionToken traces the wrapped call
+func (t *Transport) GetSubscriptionToken(ctx context.Context, subscriptionID string) (string, error) {
+	attrs := []attribute.KeyValue{AttrSubscriptionID.String(subscriptionID)}
+	return traced(ctx, t, "GetSubscriptionToken", attrs, func(ctx context.Context) (string, error) {
+		return t.TransportService.GetSubscriptionToken(ctx, subscriptionID)
+	})
+}
+
+// RefreshToken traces the wrapped call
+func (t *Transport) RefreshToken(ctx context.Context) (string, error) {
+	return traced(ctx, t, "RefreshToken", nil, func(ctx context.Context) (string, error) {
+		return t.TransportService.RefreshToken(ctx)
+	})
+}
+
+// GetUser traces the wrapped call
+func (t *Transport) GetUser(ctx context.Context) (*models.User, error) {
+	return traced(ctx, t, "GetUser", nil, func(ctx context.Context) (*models.User, error) {
+		return t.TransportService.GetUser(ctx)
+	})
+}
@@ -0,0 +1,268 @@
+package junglebus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordingMagic identifies a subscription recording, followed by a version byte
+var recordingMagic = []byte("JBREC")
+
+// recordingVersion is the current recording format version
+const recordingVersion = 1
+
+// maxRecordedEventSize guards against reading corrupt length prefixes
+const maxRecordedEventSize = 64 << 20
+
+// ErrInvalidRecording is returned when a recording cannot be parsed
+var ErrInvalidRecording = errors.New("invalid subscription recording")
+
+// recordingChannels maps channel names to their single byte code in a recording
+var recordingChannels = []string{"control", "main", "mempool"}
+
+// channelCode returns the recording code for a channel
+func channelCode(channel string) (byte, error) {
+	for i, name := range recordingChannels {
+		if name == channel {
+			return byte(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown channel %q", channel)
+}
+
+// RecordedEvent is a raw subscription event read from a recording
+type RecordedEvent struct {
+	Channel string    // control, main or mempool
+	Data    []byte    // protobuf payload as received from the server
+	Time    time.Time // arrival time
+}
+
+// EventRecorder writes raw subscription events to a compact, length-prefixed stream.
+//
+// The format is the magic "JBREC", a version byte and the start time as a uvarint of Unix
+// nanoseconds, followed by one record per event: a channel byte, the arrival time as a uvarint
+// of nanoseconds since the previous event, and the uvarint-length-prefixed protobuf payload.
+type EventRecorder struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	last   time.Time
+	buf    []byte
+}
+
+// NewEventRecorder starts a recording on the given writer
+func NewEventRecorder(w io.Writer) (*EventRecorder, error) {
+	r := &EventRecorder{
+		w:    bufio.NewWriter(w),
+		last: time.Now(),
+		buf:  make([]byte, 0, 2*binary.MaxVarintLen64+1),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		r.closer = closer
+	}
+
+	header := append([]byte{}, recordingMagic...)
+	header = append(header, recordingVersion)
+	header = binary.AppendUvarint(header, uint64(r.last.UnixNano()))
+	if _, err := r.w.Write(header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateEventRecording creates (or truncates) a recording file
+func CreateEventRecording(path string) (*EventRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewEventRecorder(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record writes one event. It is safe for concurrent use.
+func (r *EventRecorder) Record(channel string, data []byte, at time.Time) error {
+	code, err := channelCode(channel)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var delta uint64
+	if at.After(r.last) {
+		delta = uint64(at.Sub(r.last))
+		r.last = at
+	}
+
+	r.buf = append(r.buf[:0], code)
+	r.buf = binary.AppendUvarint(r.buf, delta)
+	r.buf = binary.AppendUvarint(r.buf, uint64(len(data)))
+	if _, err = r.w.Write(r.buf); err != nil {
+		return err
+	}
+	_, err = r.w.Write(data)
+	return err
+}
+
+// Flush writes any buffered events to the underlying writer
+func (r *EventRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Flush()
+}
+
+// Close flushes the recording and closes the underlying writer if it is closable
+func (r *EventRecorder) Close() error {
+	err := r.Flush()
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+	}
+	return err
+}
+
+// EventReader reads events from a recording made by EventRecorder
+type EventReader struct {
+	r    *bufio.Reader
+	last time.Time
+}
+
+// NewEventReader reads the recording header and returns a reader for its events
+func NewEventReader(r io.Reader) (*EventReader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(recordingMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecording, err)
+	}
+	if !bytes.Equal(header[:len(recordingMagic)], recordingMagic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidRecording)
+	}
+	if header[len(recordingMagic)] != recordingVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidRecording, header[len(recordingMagic)])
+	}
+	start, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecording, err)
+	}
+
+	return &EventReader{r: br, last: time.Unix(0, int64(start))}, nil
+}
+
+// Next returns the next event, or io.EOF at the end of the recording
+func (r *EventReader) Next() (*RecordedEvent, error) {
+	code, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err // io.EOF at a record boundary is a clean end
+	}
+	if int(code) >= len(recordingChannels) {
+		return nil, fmt.Errorf("%w: unknown channel code %d", ErrInvalidRecording, code)
+	}
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecording, io.ErrUnexpectedEOF)
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecording, io.ErrUnexpectedEOF)
+	}
+	if size > maxRecordedEventSize {
+		return nil, fmt.Errorf("%w: event of %d bytes", ErrInvalidRecording, size)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecording, io.ErrUnexpectedEOF)
+	}
+
+	r.last = r.last.Add(time.Duration(delta))
+	return &RecordedEvent{
+		Channel: recordingChannels[code],
+		Data:    data,
+		Time:    r.last,
+	}, nil
+}
+
+// ReplayOptions configures Client.Replay
+type ReplayOptions struct {
+	// Speed scales the original timing between events: 1 replays in real time, 10 ten times
+	// faster. 0 replays as fast as possible.
+	Speed float64
+	// LiteMode skips fetching full transactions for events recorded without a body,
+	// so a replay never touches the network
+	LiteMode bool
+	// SubscriptionID is used for logs and metrics only
+	SubscriptionID string
+	// FromBlock is the initial position reported by Subscription.Position
+	FromBlock uint64
+}
+
+// Replay feeds a recording made with SubscribeOptions.Recorder through the same event
+// processing as a live subscription, calling the handlers in the original order.
+// It returns nil at the end of the recording, or the context error if cancelled.
+func (jb *Client) Replay(ctx context.Context, source io.Reader, eventHandler EventHandler, options *ReplayOptions) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if options == nil {
+		options = &ReplayOptions{}
+	}
+	reader, err := NewEventReader(source)
+	if err != nil {
+		return err
+	}
+
+	sub := &Subscription{
+		SubscriptionID: options.SubscriptionID,
+		FromBlock:      options.FromBlock,
+		EventHandler:   eventHandler,
+		state:          stateActive,
+		client:         jb,
+		options:        &SubscribeOptions{LiteMode: options.LiteMode},
+		position:       newPosition(uint32(options.FromBlock), 0),
+		stats:          newSubscriptionStats(),
+		ctx:            ctx,
+	}
+	sub.logger = sub.log()
+
+	var previous time.Time
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if options.Speed > 0 && !previous.IsZero() {
+			wait := time.Duration(float64(event.Time.Sub(previous)) / options.Speed)
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		previous = event.Time
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		sub.processEvent(&pubEvent{Channel: event.Channel, Data: event.Data})
+	}
+}
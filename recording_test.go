@@ -0,0 +1,162 @@
+package junglebus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEventRecorder(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		rec, err := NewEventRecorder(&buf)
+		require.NoError(t, err)
+
+		start := time.Now()
+		require.NoError(t, rec.Record("control", []byte("a"), start.Add(time.Second)))
+		require.NoError(t, rec.Record("main", []byte("bb"), start.Add(3*time.Second)))
+		require.NoError(t, rec.Record("mempool", nil, start.Add(4*time.Second)))
+		require.NoError(t, rec.Close())
+
+		reader, err := NewEventReader(&buf)
+		require.NoError(t, err)
+
+		first, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "control", first.Channel)
+		assert.Equal(t, []byte("a"), first.Data)
+
+		second, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "main", second.Channel)
+		assert.Equal(t, []byte("bb"), second.Data)
+		assert.Equal(t, 2*time.Second, second.Time.Sub(first.Time))
+
+		third, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "mempool", third.Channel)
+		assert.Empty(t, third.Data)
+
+		_, err = reader.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		rec, err := NewEventRecorder(io.Discard)
+		require.NoError(t, err)
+		assert.Error(t, rec.Record("other", nil, time.Now()))
+	})
+
+	t.Run("invalid recording", func(t *testing.T) {
+		_, err := NewEventReader(bytes.NewReader([]byte("nope!!")))
+		assert.ErrorIs(t, err, ErrInvalidRecording)
+	})
+
+	t.Run("truncated recording", func(t *testing.T) {
+		var buf bytes.Buffer
+		rec, err := NewEventRecorder(&buf)
+		require.NoError(t, err)
+		require.NoError(t, rec.Record("main", []byte("payload"), time.Now()))
+		require.NoError(t, rec.Flush())
+
+		reader, err := NewEventReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+		require.NoError(t, err)
+		_, err = reader.Next()
+		assert.ErrorIs(t, err, ErrInvalidRecording)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jbrec")
+		rec, err := CreateEventRecording(path)
+		require.NoError(t, err)
+		require.NoError(t, rec.Record("main", []byte("x"), time.Now()))
+		require.NoError(t, rec.Close())
+	})
+}
+
+func TestSubscription_Recorder(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewEventRecorder(&buf)
+	require.NoError(t, err)
+
+	handler, _, _, _ := newTestEventHandler()
+	sub := &Subscription{
+		EventHandler: handler,
+		eventQueue:   newEventQueue(10),
+		position:     newPosition(0, 0),
+		options:      &SubscribeOptions{Recorder: rec},
+	}
+	go sub.handleEvents()
+
+	status, err := proto.Marshal(&models.ControlResponse{StatusCode: uint32(SubscriptionBlockDone), Block: 5})
+	require.NoError(t, err)
+	sub.addToQueue(&pubEvent{Channel: "control", Data: status})
+	require.NoError(t, sub.Unsubscribe())
+
+	reader, err := NewEventReader(&buf)
+	require.NoError(t, err)
+	event, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "control", event.Channel)
+	assert.Equal(t, status, event.Data)
+}
+
+func TestClient_Replay(t *testing.T) {
+	client, err := New()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	rec, err := NewEventRecorder(&buf)
+	require.NoError(t, err)
+
+	start := time.Now()
+	tx, err := proto.Marshal(&models.TransactionResponse{Id: "tx1", BlockHeight: 100, Transaction: []byte{1}})
+	require.NoError(t, err)
+	mempool, err := proto.Marshal(&models.TransactionResponse{Id: "tx2", Transaction: []byte{2}})
+	require.NoError(t, err)
+	status, err := proto.Marshal(&models.ControlResponse{StatusCode: uint32(SubscriptionBlockDone), Block: 100})
+	require.NoError(t, err)
+	require.NoError(t, rec.Record("main", tx, start))
+	require.NoError(t, rec.Record("mempool", mempool, start.Add(100*time.Millisecond)))
+	require.NoError(t, rec.Record("control", status, start.Add(200*time.Millisecond)))
+	require.NoError(t, rec.Flush())
+	recording := buf.Bytes()
+
+	var order []string
+	handler := EventHandler{
+		OnTransaction: func(tx *models.TransactionResponse) { order = append(order, "tx:"+tx.Id) },
+		OnMempool:     func(tx *models.TransactionResponse) { order = append(order, "mempool:"+tx.Id) },
+		OnStatus:      func(s *models.ControlResponse) { order = append(order, "status") },
+	}
+
+	t.Run("as fast as possible", func(t *testing.T) {
+		order = nil
+		err := client.Replay(context.Background(), bytes.NewReader(recording), handler, &ReplayOptions{LiteMode: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tx:tx1", "mempool:tx2", "status"}, order)
+	})
+
+	t.Run("accelerated", func(t *testing.T) {
+		order = nil
+		started := time.Now()
+		err := client.Replay(context.Background(), bytes.NewReader(recording), handler, &ReplayOptions{Speed: 4, LiteMode: true})
+		require.NoError(t, err)
+		assert.Len(t, order, 3)
+		assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := client.Replay(ctx, bytes.NewReader(recording), handler, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
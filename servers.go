@@ -0,0 +1,64 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// ServerStatus returns the health of each server configured with WithServers, or nil when the
+// client uses a single server
+func (jb *Client) ServerStatus() []transports.ServerStatus {
+	if servers, ok := transports.As[transports.ServerService](jb.transport); ok {
+		return servers.ServerStatus()
+	}
+	return nil
+}
+
+// ProbeServers health checks every server configured with WithServers now and returns their
+// health, or nil when the client uses a single server
+func (jb *Client) ProbeServers(ctx context.Context) ([]transports.ServerStatus, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	if servers, ok := transports.As[transports.ServerService](jb.transport); ok {
+		return servers.ProbeServers(ctx), nil
+	}
+	return nil, nil
+}
+
+// ConcurrencyStats returns the current HTTP concurrency limit and how many requests are in
+// flight and waiting
+func (jb *Client) ConcurrencyStats() transports.ConcurrencyStats {
+	if t, ok := transports.As[transports.ConcurrencyService](jb.transport); ok {
+		return t.ConcurrencyStats()
+	}
+	return transports.ConcurrencyStats{}
+}
+
+// CoalescingStats returns how many GET requests shared the response of an identical request
+// in flight
+func (jb *Client) CoalescingStats() transports.CoalescingStats {
+	if t, ok := transports.As[transports.CoalescingService](jb.transport); ok {
+		return t.CoalescingStats()
+	}
+	return transports.CoalescingStats{}
+}
+
+// CircuitStates returns the circuit breaker state of each route class requested so far, or nil
+// without WithCircuitBreaker
+func (jb *Client) CircuitStates() map[string]transports.CircuitState {
+	if t, ok := transports.As[transports.CircuitBreakerService](jb.transport); ok {
+		return t.CircuitStates()
+	}
+	return nil
+}
+
+// selectServer returns the server to use for a new connection and whether to use SSL
+func (jb *Client) selectServer() (string, bool) {
+	if servers, ok := transports.As[transports.ServerService](jb.transport); ok {
+		return servers.SelectServer()
+	}
+	return jb.transport.GetServerURL(), jb.transport.IsSSL()
+}
@@ -0,0 +1,93 @@
+package junglebus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Servers(t *testing.T) {
+	ctx := context.Background()
+	// The first websocket connection is refused, later ones are accepted (but never answered)
+	var websockets int32
+	var refused string
+	newServer := func() *httptest.Server {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/connection/websocket" {
+				_, _ = w.Write([]byte(`{"height": 800000}`))
+				return
+			}
+			if atomic.AddInt32(&websockets, 1) == 1 {
+				refused = r.Host
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			for {
+				if _, _, err = conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	a, b := newServer(), newServer()
+
+	client, err := New(
+		WithServers([]string{a.URL, b.URL}, transports.ServerPolicy{Selection: transports.SelectLatency}),
+		WithToken("token"),
+	)
+	require.NoError(t, err)
+
+	tip, err := client.GetChainTip(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(800000), tip.Height)
+
+	statuses, err := client.ProbeServers(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Healthy)
+	assert.True(t, statuses[1].Healthy)
+	assert.Len(t, client.ServerStatus(), 2)
+
+	_, err = client.ProbeServers(getNilContext())
+	require.Error(t, err)
+
+	t.Run("subscription fails over", func(t *testing.T) {
+		var failover string
+		sub, err := client.SubscribeWithQueue(ctx, "sub", 100, 0, EventHandler{
+			OnTransaction: func(*models.TransactionResponse) {},
+			OnStatus: func(status *models.ControlResponse) {
+				if status.Status == "failover" {
+					failover = status.Message
+				}
+			},
+		}, &SubscribeOptions{ChainTipInterval: time.Hour})
+		require.NoError(t, err)
+		defer func() { _ = sub.Unsubscribe() }()
+
+		assert.NotEqual(t, refused, sub.Server())
+		assert.Contains(t, failover, "at block 100, page 0")
+		stats := sub.Stats()
+		assert.Equal(t, uint64(1), stats.Failovers)
+		assert.Equal(t, sub.Server(), stats.Server)
+
+		for _, status := range client.ServerStatus() {
+			assert.Equal(t, status.Server != refused, status.Healthy, status.Server)
+		}
+	})
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
 	"github.com/centrifugal/centrifuge-go"
 	"google.golang.org/protobuf/proto"
 )
@@ -34,28 +36,68 @@ type Subscription struct {
 	// Connection management
 	centrifugeClient *centrifuge.Client
 	channels         *channelManager
-	mainChannelName  string // Track current main channel name for reconnect updates
-	hasConnected     bool   // Track if we've ever successfully connected
+	mainChannelName  string        // Track current main channel name for reconnect updates
+	mainGen          atomic.Uint64 // Generation of the main channel, incremented by Seek
+	seeking          chan struct{} // Closed once the Seek in progress replaced the main channel or failed
+	hasConnected     bool          // Track if we've ever successfully connected
+	server           string        // Server the connection is made to
+	connectErrors    int           // Connection errors since the last successful connect
+	switching        atomic.Bool   // Held while failing over or recreating the channels
+	reconnecting     atomic.Bool   // Set while reconnecting after the connection was lost
+	completed        atomic.Bool   // Set once ToBlock was reached
+	attempt          chan error    // Receives the outcome of the reconnect attempt in progress
 
 	// Event processing
 	eventQueue *eventQueue
+	stats      *subscriptionStats
+	logger     *slog.Logger
 
 	// Lifecycle management
 	ctx    context.Context
 	cancel context.CancelFunc
 	done   chan struct{}
+	err    error // Terminal error, set before done is closed
 }
 
 // pubEvent represents an event received from a subscription channel
 type pubEvent struct {
 	Channel string
 	Data    []byte
+
+	prefetch *prefetch     // set when the full transaction is fetched ahead
+	gen      uint64        // main channel generation the event was received on
+	seek     chan struct{} // set on the marker queued by Seek, closed when it is reached
 }
 
 // SubscribeOptions configures subscription behavior
 type SubscribeOptions struct {
 	QueueSize uint32
 	LiteMode  bool
+
+	// ToBlock, if set, is the last block of the subscription. Once it is done no more events are
+	// delivered, the subscription unsubscribes and Completed reports true.
+	ToBlock uint32
+
+	// Prefetch is the number of full transactions fetched at the same time when not in lite mode,
+	// for queued events as well as the one being handled (DefaultPrefetch if 0, negative to fetch
+	// each one as its event is handled). Events are still handled one at a time, in order.
+	Prefetch int
+
+	// ChainTipInterval is how often the chain tip is polled to compute lag (DefaultChainTipInterval if 0)
+	ChainTipInterval time.Duration
+
+	// StallTimeout is how long the subscription may go without main or control events while it
+	// is behind the chain tip polled every ChainTipInterval before its channels are recreated from
+	// the current position (DefaultStallTimeout if 0, negative to disable)
+	StallTimeout time.Duration
+
+	// Connection configures the websocket timeouts and how the connection is re-established
+	// after it was lost
+	Connection ConnectionOptions
+
+	// Recorder, if set, receives every raw event as it arrives so the stream can be replayed
+	// later with Client.Replay. It is flushed on Unsubscribe but not closed.
+	Recorder *EventRecorder
 }
 
 // Unsubscribe closes the subscription and releases all resources.
@@ -76,16 +118,20 @@ func (s *Subscription) Unsubscribe() error {
 
 	var errs []error
 
+	s.mu.RLock()
+	channels, centrifugeClient := s.channels, s.centrifugeClient
+	s.mu.RUnlock()
+
 	// Unsubscribe from all channels
-	if s.channels != nil {
-		if err := s.channels.UnsubscribeAll(); err != nil {
+	if channels != nil {
+		if err := channels.UnsubscribeAll(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
 	// Close the centrifuge client
-	if s.centrifugeClient != nil {
-		if err := s.centrifugeClient.Disconnect(); err != nil {
+	if centrifugeClient != nil {
+		if err := centrifugeClient.Disconnect(); err != nil {
 			errs = append(errs, fmt.Errorf("disconnect: %w", err))
 		}
 	}
@@ -96,6 +142,13 @@ func (s *Subscription) Unsubscribe() error {
 		s.eventQueue.Wait()
 	}
 
+	// Flush any recorded events
+	if s.options != nil && s.options.Recorder != nil {
+		if err := s.options.Recorder.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("flush recorder: %w", err))
+		}
+	}
+
 	// Signal completion
 	if s.done != nil {
 		close(s.done)
@@ -131,6 +184,17 @@ func (s *Subscription) setState(state subscriptionState) {
 	s.state = state
 }
 
+// log returns the subscription logger, tagged with the subscription ID
+func (s *Subscription) log() *slog.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	if s.client != nil {
+		return s.client.log().With(slog.String("subscription_id", s.SubscriptionID))
+	}
+	return transports.ResolveLogger(nil, false)
+}
+
 // getState returns the current state (internal use)
 func (s *Subscription) getState() subscriptionState {
 	s.mu.RLock()
@@ -140,6 +204,12 @@ func (s *Subscription) getState() subscriptionState {
 
 // addToQueue safely adds an event to the processing queue
 func (s *Subscription) addToQueue(event *pubEvent) {
+	s.stats.recordArrival(event.Channel, time.Now())
+	if s.options != nil && s.options.Recorder != nil {
+		if err := s.options.Recorder.Record(event.Channel, event.Data, time.Now()); err != nil {
+			s.reportError(fmt.Errorf("record event: %w", err))
+		}
+	}
 	if !s.eventQueue.SendBlocking(event) {
 		// Queue is closed, subscription is shutting down
 		return
@@ -148,6 +218,10 @@ func (s *Subscription) addToQueue(event *pubEvent) {
 
 // handleEvents processes events from the queue (runs in a goroutine)
 func (s *Subscription) handleEvents() {
+	if window := s.prefetchWindow(); window > 1 {
+		s.handleEventsPrefetching(window)
+		return
+	}
 	for event := range s.eventQueue.Channel() {
 		s.processEvent(event)
 		s.eventQueue.Done()
@@ -156,103 +230,144 @@ func (s *Subscription) handleEvents() {
 
 // processEvent handles a single event with proper error handling
 func (s *Subscription) processEvent(event *pubEvent) {
+	if s.skipEvent(event) {
+		return
+	}
+	start := time.Now()
+	defer func() {
+		latency := time.Since(start)
+		s.stats.recordEvent(event.Channel, latency)
+		s.observeEvent(event.Channel)
+		s.log().Debug("event processed", slog.String("channel", event.Channel), slog.Duration("latency", latency))
+	}()
+
 	// Recover from panics in event handlers
 	defer func() {
 		if r := recover(); r != nil {
-			if s.EventHandler.OnError != nil {
-				s.EventHandler.OnError(fmt.Errorf("panic in event handler: %v", r))
-			}
+			s.reportError(fmt.Errorf("panic in event handler: %v", r))
 		}
 	}()
 
 	switch event.Channel {
 	case "control":
-		s.handleControlEvent(event.Data)
+		s.handleControlEvent(event)
 	case "main":
-		s.handleTransactionEvent(event.Data)
+		s.handleTransactionEvent(event)
 	case "mempool":
-		s.handleMempoolEvent(event.Data)
+		s.handleMempoolEvent(event)
 	}
 }
 
 // handleControlEvent processes control/status messages
-func (s *Subscription) handleControlEvent(data []byte) {
+func (s *Subscription) handleControlEvent(event *pubEvent) {
 	status := &models.ControlResponse{}
-	if err := proto.Unmarshal(data, status); err != nil {
-		if s.EventHandler.OnError != nil {
-			s.EventHandler.OnError(fmt.Errorf("unmarshal control: %w", err))
-		}
+	if err := proto.Unmarshal(event.Data, status); err != nil {
+		s.reportError(fmt.Errorf("unmarshal control: %w", err))
 		return
 	}
 
-	// Update position based on status
-	switch StatusCode(status.StatusCode) {
-	case SubscriptionBlockDone:
-		s.position.AdvanceBlock(status.Block + 1)
-	case SubscriptionPageDone:
-		s.position.AdvancePage(status.Block, status.Transactions+1)
+	s.log().Debug("status",
+		slog.Uint64("code", uint64(status.StatusCode)),
+		slog.String("status", status.Status),
+		slog.Uint64("block", uint64(status.Block)),
+		slog.Uint64("page", status.Transactions),
+	)
+
+	// Update position based on status, unless it arrived before a Seek
+	if event.gen == s.mainGen.Load() {
+		switch StatusCode(status.StatusCode) {
+		case SubscriptionBlockDone:
+			s.position.AdvanceBlock(status.Block + 1)
+			s.checkLag()
+		case SubscriptionPageDone:
+			s.position.AdvancePage(status.Block, status.Transactions+1)
+		}
 	}
 
 	if s.EventHandler.OnStatus != nil {
 		s.EventHandler.OnStatus(status)
 	}
+	if StatusCode(status.StatusCode) == SubscriptionBlockDone {
+		s.checkComplete(status.Block)
+	}
 }
 
 // handleTransactionEvent processes block transaction messages
-func (s *Subscription) handleTransactionEvent(data []byte) {
+func (s *Subscription) handleTransactionEvent(event *pubEvent) {
 	tx := &models.TransactionResponse{}
-	if err := proto.Unmarshal(data, tx); err != nil {
-		if s.EventHandler.OnError != nil {
-			s.EventHandler.OnError(fmt.Errorf("unmarshal transaction: %w", err))
-		}
+	if err := proto.Unmarshal(event.Data, tx); err != nil {
+		s.reportError(fmt.Errorf("unmarshal transaction: %w", err))
 		return
 	}
 
+	s.log().Debug("transaction", slog.String("txid", tx.Id), slog.Uint64("block", uint64(tx.BlockHeight)))
+
+	ctx, endEvent := s.beginEvent(event, tx)
+	var fetchErr error
+	defer func() {
+		endEvent(fetchErr)
+	}()
+
 	// Fetch full transaction data if needed
+	var full *models.Transaction
 	if len(tx.Transaction) == 0 && !s.options.LiteMode {
-		txData, err := s.client.GetTransaction(s.ctx, tx.Id)
+		txData, err := s.fetchTransaction(ctx, event, tx.Id)
 		if err != nil {
-			if s.EventHandler.OnError != nil {
-				s.EventHandler.OnError(fmt.Errorf("fetch transaction %s: %w", tx.Id, err))
-			}
+			fetchErr = fmt.Errorf("fetch transaction %s: %w", tx.Id, err)
+			s.reportError(fetchErr)
 			return
 		}
 		tx.Transaction = txData.Transaction
+		full = txData
 	}
 
 	// Update position
 	s.position.SetBlock(tx.BlockHeight)
+	s.stats.recordBlockTime(tx.BlockTime)
 
 	if s.EventHandler.OnTransaction != nil {
 		s.EventHandler.OnTransaction(tx)
 	}
+	if s.EventHandler.OnTransactionEvent != nil {
+		s.EventHandler.OnTransactionEvent(s.newTransactionEvent(tx, full))
+	}
 }
 
 // handleMempoolEvent processes mempool transaction messages
-func (s *Subscription) handleMempoolEvent(data []byte) {
+func (s *Subscription) handleMempoolEvent(event *pubEvent) {
 	tx := &models.TransactionResponse{}
-	if err := proto.Unmarshal(data, tx); err != nil {
-		if s.EventHandler.OnError != nil {
-			s.EventHandler.OnError(fmt.Errorf("unmarshal mempool tx: %w", err))
-		}
+	if err := proto.Unmarshal(event.Data, tx); err != nil {
+		s.reportError(fmt.Errorf("unmarshal mempool tx: %w", err))
 		return
 	}
 
+	s.log().Debug("mempool transaction", slog.String("txid", tx.Id))
+
+	ctx, endEvent := s.beginEvent(event, tx)
+	var fetchErr error
+	defer func() {
+		endEvent(fetchErr)
+	}()
+
 	// Fetch full transaction data if needed
+	var full *models.Transaction
 	if len(tx.Transaction) == 0 && !s.options.LiteMode {
-		txData, err := s.client.GetTransaction(s.ctx, tx.Id)
+		txData, err := s.fetchTransaction(ctx, event, tx.Id)
 		if err != nil {
-			if s.EventHandler.OnError != nil {
-				s.EventHandler.OnError(fmt.Errorf("fetch mempool tx %s: %w", tx.Id, err))
-			}
+			fetchErr = fmt.Errorf("fetch mempool tx %s: %w", tx.Id, err)
+			s.reportError(fetchErr)
 			return
 		}
 		tx.Transaction = txData.Transaction
+		full = txData
 	}
 
 	if s.EventHandler.OnMempool != nil {
 		s.EventHandler.OnMempool(tx)
 	}
+	if s.EventHandler.OnMempoolEvent != nil {
+		s.EventHandler.OnMempoolEvent(s.newTransactionEvent(tx, full))
+	}
 }
 
 // setupCentrifugeHandlers configures all event handlers for the centrifuge client
@@ -261,6 +376,9 @@ func (s *Subscription) setupCentrifugeHandlers() {
 
 	c.OnConnecting(func(e centrifuge.ConnectingEvent) {
 		s.setState(stateConnecting)
+		if e.Code != 0 {
+			s.onConnectionLost(c, e) // not a call to Connect
+		}
 
 		status := "connecting"
 		message := "Connecting to server"
@@ -288,15 +406,22 @@ func (s *Subscription) setupCentrifugeHandlers() {
 		s.mu.Lock()
 		isReconnect := s.hasConnected
 		s.hasConnected = true
+		s.connectErrors = 0
 		s.mu.Unlock()
 
+		block, page := s.position.Get()
+		s.log().Info("connected", slog.Bool("reconnect", isReconnect),
+			slog.Uint64("block", uint64(block)), slog.Uint64("page", page))
+
+		if isReconnect {
+			s.stats.recordReconnect()
+			s.observeReconnect()
+		}
+
 		// On reconnect, update the main channel to use current position
-		if isReconnect && s.EventHandler.OnTransaction != nil && s.mainChannelName != "" {
+		if isReconnect && s.EventHandler.handlesMain() && s.mainChannelName != "" {
 			if err := s.updateMainChannelPosition(); err != nil {
-				log.Printf("Failed to update main channel on reconnect: %v", err)
-				if s.EventHandler.OnError != nil {
-					s.EventHandler.OnError(fmt.Errorf("reconnect channel update: %w", err))
-				}
+				s.reportError(fmt.Errorf("reconnect channel update: %w", err))
 			}
 		}
 
@@ -307,14 +432,30 @@ func (s *Subscription) setupCentrifugeHandlers() {
 				Message:    "Connected to server",
 			})
 		}
+		if isReconnect && s.EventHandler.OnReconnected != nil {
+			s.EventHandler.OnReconnected(s.position.Get())
+		}
+		s.endAttempt(nil)
 	})
 
 	c.OnDisconnected(func(e centrifuge.DisconnectedEvent) {
+		if !s.isCurrent(c) {
+			return // the connection was replaced by a failover
+		}
+		if e.Code != 0 {
+			s.endAttempt(fmt.Errorf("disconnected: %s", e.Reason)) // not a call to Disconnect
+		}
+		if s.reconnecting.Load() {
+			return // the connection is between reconnect attempts
+		}
+
 		// Don't change state if we're closing
 		if s.getState() != stateClosed {
 			s.setState(stateDisconnected)
 		}
 
+		s.log().Info("disconnected", slog.Uint64("code", uint64(e.Code)), slog.String("reason", e.Reason))
+
 		if s.EventHandler.OnStatus != nil {
 			s.EventHandler.OnStatus(&models.ControlResponse{
 				StatusCode: uint32(StatusDisconnected),
@@ -325,6 +466,13 @@ func (s *Subscription) setupCentrifugeHandlers() {
 	})
 
 	c.OnError(func(e centrifuge.ErrorEvent) {
+		s.stats.recordError(e.Error)
+		s.log().Warn("connection error", slog.Any("error", e.Error))
+		s.onConnectionError(c, e.Error)
+		if isConnectError(e.Error) {
+			s.endAttempt(e.Error)
+		}
+
 		if s.EventHandler.OnStatus != nil {
 			s.EventHandler.OnStatus(&models.ControlResponse{
 				StatusCode: uint32(StatusError),
@@ -335,7 +483,7 @@ func (s *Subscription) setupCentrifugeHandlers() {
 	})
 
 	c.OnMessage(func(e centrifuge.MessageEvent) {
-		log.Printf("Message from server: %s", string(e.Data))
+		s.log().Debug("message from server", slog.Int("bytes", len(e.Data)))
 	})
 
 	c.OnSubscribed(func(e centrifuge.ServerSubscribedEvent) {
@@ -371,13 +519,14 @@ func (s *Subscription) setupCentrifugeHandlers() {
 	})
 
 	c.OnPublication(func(e centrifuge.ServerPublicationEvent) {
-		log.Printf("Publication from server-side channel %s: %s (offset %d)", e.Channel, e.Data, e.Offset)
+		s.log().Debug("server-side publication", slog.String("channel", e.Channel),
+			slog.Uint64("offset", e.Offset), slog.Int("bytes", len(e.Data)))
 		if strings.Contains(e.Channel, ":control") {
-			s.addToQueue(&pubEvent{Channel: "control", Data: e.Data})
+			s.addToQueue(&pubEvent{Channel: "control", Data: e.Data, gen: s.mainGen.Load()})
 		} else if strings.Contains(e.Channel, ":mempool") {
 			s.addToQueue(&pubEvent{Channel: "mempool", Data: e.Data})
 		} else {
-			s.addToQueue(&pubEvent{Channel: "main", Data: e.Data})
+			s.addToQueue(&pubEvent{Channel: "main", Data: e.Data, gen: s.mainGenFor(e.Channel)})
 		}
 	})
 
@@ -402,6 +551,27 @@ func (s *Subscription) setupCentrifugeHandlers() {
 	})
 }
 
+// mainHandler returns the publication handler of a main channel, tagging its events with the
+// current main channel generation
+func (s *Subscription) mainHandler() func(e centrifuge.PublicationEvent) {
+	gen := s.mainGen.Load()
+	return func(e centrifuge.PublicationEvent) {
+		s.addToQueue(&pubEvent{Channel: "main", Data: e.Data, gen: gen})
+	}
+}
+
+// mainGenFor returns the generation of a server-side publication on a main channel: the
+// current one for the current main channel, an older one for a channel since replaced
+func (s *Subscription) mainGenFor(channel string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	gen := s.mainGen.Load()
+	if channel != s.mainChannelName {
+		gen--
+	}
+	return gen
+}
+
 // getSubType returns the subscription type prefix based on options
 func (s *Subscription) getSubType() string {
 	if s.options.LiteMode {
@@ -413,6 +583,12 @@ func (s *Subscription) getSubType() string {
 // updateMainChannelPosition replaces the main channel subscription with a new one
 // using the current position. Called on reconnect to avoid replaying old data.
 func (s *Subscription) updateMainChannelPosition() error {
+	// A seek, failover or stall recovery in progress sets up the channels itself
+	if !s.switching.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer s.switching.Store(false)
+
 	if s.mainChannelName == "" {
 		return nil // No main channel to update
 	}
@@ -426,21 +602,14 @@ func (s *Subscription) updateMainChannelPosition() error {
 		return nil
 	}
 
-	log.Printf("Updating main channel from %s to %s", s.mainChannelName, newChannelName)
+	s.log().Info("updating main channel", slog.String("from", s.mainChannelName), slog.String("to", newChannelName),
+		slog.Uint64("block", uint64(block)), slog.Uint64("page", page))
 
 	// Replace the subscription with new position
-	sub, err := s.channels.ReplaceSubscription(s.mainChannelName, newChannelName, func(e centrifuge.PublicationEvent) {
-		s.addToQueue(&pubEvent{Channel: "main", Data: e.Data})
-	})
-	if err != nil {
+	if err := s.channels.ReplaceSubscription(s.mainChannelName, newChannelName, s.mainHandler()); err != nil {
 		return err
 	}
 
-	// Subscribe to the new channel
-	if err := sub.Subscribe(); err != nil {
-		return fmt.Errorf("subscribe to new channel: %w", err)
-	}
-
 	// Update tracked name
 	s.mu.Lock()
 	s.mainChannelName = newChannelName
@@ -457,25 +626,25 @@ func (s *Subscription) setupChannels() error {
 	// Control channel
 	controlChannel := fmt.Sprintf("%s:%s:control", subType, s.SubscriptionID)
 	if _, err := s.channels.CreateSubscription(controlChannel, func(e centrifuge.PublicationEvent) {
-		s.addToQueue(&pubEvent{Channel: "control", Data: e.Data})
+		s.addToQueue(&pubEvent{Channel: "control", Data: e.Data, gen: s.mainGen.Load()})
 	}); err != nil {
 		return fmt.Errorf("create control channel: %w", err)
 	}
 
 	// Main transaction channel (if handler provided)
-	if s.EventHandler.OnTransaction != nil {
+	if s.EventHandler.handlesMain() {
 		mainChannel := fmt.Sprintf("%s:%s:%d:%d", subType, s.SubscriptionID, block, page)
-		if _, err := s.channels.CreateSubscription(mainChannel, func(e centrifuge.PublicationEvent) {
-			s.addToQueue(&pubEvent{Channel: "main", Data: e.Data})
-		}); err != nil {
+		if _, err := s.channels.CreateSubscription(mainChannel, s.mainHandler()); err != nil {
 			return fmt.Errorf("create main channel: %w", err)
 		}
 		// Track main channel name for reconnect updates
+		s.mu.Lock()
 		s.mainChannelName = mainChannel
+		s.mu.Unlock()
 	}
 
 	// Mempool channel (if handler provided)
-	if s.EventHandler.OnMempool != nil {
+	if s.EventHandler.handlesMempool() {
 		mempoolChannel := fmt.Sprintf("%s:%s:mempool", subType, s.SubscriptionID)
 		if _, err := s.channels.CreateSubscription(mempoolChannel, func(e centrifuge.PublicationEvent) {
 			s.addToQueue(&pubEvent{Channel: "mempool", Data: e.Data})
@@ -508,6 +677,28 @@ func (jb *Client) Subscribe(ctx context.Context, subscriptionID string, fromBloc
 	})
 }
 
+// newCentrifugeClient creates a websocket client for a server
+func (jb *Client) newCentrifugeClient(ctx context.Context, server string, useSSL bool, token string, conn ConnectionOptions) *centrifuge.Client {
+	protocol := "wss"
+	if !useSSL {
+		protocol = "ws"
+	}
+	url := fmt.Sprintf("%s://%s/connection/websocket?format=protobuf", protocol, server)
+
+	return centrifuge.NewProtobufClient(url, centrifuge.Config{
+		Token: token,
+		GetToken: func(event centrifuge.ConnectionTokenEvent) (string, error) {
+			return jb.transport.RefreshToken(ctx)
+		},
+		Name:               "go-junglebus",
+		ReadTimeout:        conn.ReadTimeout,
+		WriteTimeout:       conn.WriteTimeout,
+		HandshakeTimeout:   conn.HandshakeTimeout,
+		MaxServerPingDelay: conn.MaxServerPingDelay,
+		EnableCompression:  true,
+	})
+}
+
 // SubscribeWithQueue creates a subscription with custom queue options
 func (jb *Client) SubscribeWithQueue(ctx context.Context, subscriptionID string, fromBlock uint64, fromPage uint64, eventHandler EventHandler, options *SubscribeOptions) (*Subscription, error) {
 	// Default options
@@ -517,6 +708,9 @@ func (jb *Client) SubscribeWithQueue(ctx context.Context, subscriptionID string,
 	if options.QueueSize == 0 {
 		options.QueueSize = 100000
 	}
+	if options.ToBlock > 0 && uint64(options.ToBlock) < fromBlock {
+		return nil, fmt.Errorf("to block %d is before from block %d", options.ToBlock, fromBlock)
+	}
 
 	// Create cancellable context
 	subCtx, cancel := context.WithCancel(ctx)
@@ -534,26 +728,9 @@ func (jb *Client) SubscribeWithQueue(ctx context.Context, subscriptionID string,
 		}
 	}
 
-	// Build WebSocket URL
-	protocol := "wss"
-	if !jb.transport.IsSSL() {
-		protocol = "ws"
-	}
-	url := fmt.Sprintf("%s://%s/connection/websocket?format=protobuf", protocol, jb.transport.GetServerURL())
-
 	// Create centrifuge client
-	centrifugeClient := centrifuge.NewProtobufClient(url, centrifuge.Config{
-		Token: token,
-		GetToken: func(event centrifuge.ConnectionTokenEvent) (string, error) {
-			return jb.transport.RefreshToken(subCtx)
-		},
-		Name:               "go-junglebus",
-		ReadTimeout:        30 * time.Second,
-		WriteTimeout:       2 * time.Second,
-		HandshakeTimeout:   30 * time.Second,
-		MaxServerPingDelay: 30 * time.Second,
-		EnableCompression:  true,
-	})
+	server, useSSL := jb.selectServer()
+	centrifugeClient := jb.newCentrifugeClient(subCtx, server, useSSL, token, options.Connection.withDefaults())
 
 	// Create subscription
 	sub := &Subscription{
@@ -566,7 +743,10 @@ func (jb *Client) SubscribeWithQueue(ctx context.Context, subscriptionID string,
 		position:         newPosition(uint32(fromBlock), fromPage),
 		centrifugeClient: centrifugeClient,
 		channels:         newChannelManager(centrifugeClient),
+		server:           server,
 		eventQueue:       newEventQueue(options.QueueSize),
+		stats:            newSubscriptionStats(),
+		logger:           jb.log().With(slog.String("subscription_id", subscriptionID)),
 		ctx:              subCtx,
 		cancel:           cancel,
 		done:             make(chan struct{}),
@@ -584,20 +764,22 @@ func (jb *Client) SubscribeWithQueue(ctx context.Context, subscriptionID string,
 		return nil, err
 	}
 
-	// Connect to server
-	if err := centrifugeClient.Connect(); err != nil {
+	// Connect to server and subscribe to all channels, failing over to other servers
+	if err := sub.connect(); err != nil {
 		sub.Unsubscribe()
-		return nil, fmt.Errorf("connect: %w", err)
-	}
-
-	// Subscribe to all channels
-	if err := sub.channels.SubscribeAll(); err != nil {
-		sub.Unsubscribe()
-		return nil, fmt.Errorf("subscribe channels: %w", err)
+		return nil, err
 	}
 
 	sub.setState(stateActive)
 
+	// Track lag against the chain tip
+	go sub.pollChainTip(subCtx, options.ChainTipInterval)
+
+	// Recreate the channels if events stop while behind the chain tip
+	if timeout := sub.stallTimeout(); timeout > 0 {
+		go sub.watchStalls(subCtx, timeout)
+	}
+
 	// Store reference on client
 	jb.mu.Lock()
 	jb.subscription = sub
@@ -0,0 +1,139 @@
+package junglebus
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/centrifugal/centrifuge-go"
+)
+
+// failoverAfterErrors is the number of consecutive connection errors after which a
+// subscription moves to another server, when the client has several
+const failoverAfterErrors = 3
+
+// Server returns the server the subscription is connected (or connecting) to
+func (s *Subscription) Server() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.server
+}
+
+// isCurrent reports whether c is the subscription's current connection
+func (s *Subscription) isCurrent(c *centrifuge.Client) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return c == s.centrifugeClient
+}
+
+// onConnectionError counts errors from the current connection and fails over to another
+// server once there are too many without a successful connect
+func (s *Subscription) onConnectionError(c *centrifuge.Client, err error) {
+	s.mu.Lock()
+	if c != s.centrifugeClient || s.state == stateClosed {
+		s.mu.Unlock()
+		return // an error from a connection that was replaced
+	}
+	s.connectErrors++
+	failover := s.connectErrors >= failoverAfterErrors
+	s.mu.Unlock()
+
+	if failover {
+		go s.failover(err)
+	}
+}
+
+// failover moves the subscription to the next server after repeated connection errors,
+// resuming from the current block and page. With no other server available it does nothing
+// and the current connection keeps retrying.
+func (s *Subscription) failover(cause error) {
+	if !s.switching.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.switching.Store(false)
+
+	if !s.switchServer(cause, nil) {
+		return
+	}
+	if err := s.connect(); err != nil {
+		s.reportError(fmt.Errorf("failover: %w", err))
+	}
+}
+
+// connect connects to the current server and subscribes to the channels. If the server
+// cannot be reached it tries the other servers in turn, returning the error once none is left.
+func (s *Subscription) connect() error {
+	tried := make(map[string]struct{})
+	for {
+		s.mu.RLock()
+		c, channels, server := s.centrifugeClient, s.channels, s.server
+		s.mu.RUnlock()
+		tried[server] = struct{}{}
+
+		err := c.Connect()
+		if err == nil {
+			if err = channels.SubscribeAll(); err != nil {
+				return fmt.Errorf("subscribe channels: %w", err)
+			}
+			return nil
+		}
+		err = fmt.Errorf("connect: %w", err)
+		if !s.switchServer(err, tried) {
+			return err
+		}
+	}
+}
+
+// switchServer marks the current server down and replaces the connection with one to the
+// next server, set up from the current position but not yet connected. It returns false if
+// there is no other server, or only ones in tried.
+func (s *Subscription) switchServer(cause error, tried map[string]struct{}) bool {
+	s.mu.RLock()
+	current, previous := s.server, s.centrifugeClient
+	s.mu.RUnlock()
+
+	servers, ok := transports.As[transports.ServerService](s.client.transport)
+	if !ok {
+		return false // a single server
+	}
+	servers.MarkServerDown(current, cause)
+	server, useSSL := servers.SelectServer()
+	if _, ok := tried[server]; ok || server == current {
+		return false
+	}
+
+	block, page := s.position.Get()
+	s.log().Warn("failing over", slog.String("from", current), slog.String("to", server),
+		slog.Uint64("block", uint64(block)), slog.Uint64("page", page), slog.Any("error", cause))
+	if s.EventHandler.OnStatus != nil {
+		s.EventHandler.OnStatus(&models.ControlResponse{
+			StatusCode: uint32(StatusConnecting),
+			Status:     "failover",
+			Message:    fmt.Sprintf("Failing over to %s at block %d, page %d", server, block, page),
+		})
+	}
+
+	next := s.client.newCentrifugeClient(s.ctx, server, useSSL, s.client.transport.GetToken(), s.connection())
+	s.mu.Lock()
+	if s.state == stateClosed {
+		s.mu.Unlock()
+		return false
+	}
+	s.centrifugeClient = next
+	s.channels = newChannelManager(next)
+	s.server = server
+	s.connectErrors = 0
+	s.mu.Unlock()
+
+	// Events from the previous connection are ignored from here on
+	previous.Close()
+	s.stats.recordFailover()
+
+	s.setupCentrifugeHandlers()
+	if err := s.setupChannels(); err != nil {
+		s.reportError(fmt.Errorf("failover to %s: %w", server, err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package junglebus
+
+import (
+	"context"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultPrefetch is the number of full transactions a non-lite subscription fetches at the
+// same time, for the event being handled and those queued behind it
+const DefaultPrefetch = 16
+
+// prefetch is a full transaction being fetched before its event is handled
+type prefetch struct {
+	ctx      context.Context // event context from the tracer, used for the fetch
+	endEvent func(err error)
+	done     chan struct{}
+	tx       *models.Transaction
+	err      error
+}
+
+// prefetchWindow returns how many transactions to fetch at the same time, or 0 to fetch each
+// one only when its event is handled
+func (s *Subscription) prefetchWindow() int {
+	if s.options == nil || s.options.LiteMode || s.client == nil || s.options.Prefetch < 0 {
+		return 0
+	}
+	if s.options.Prefetch == 0 {
+		return DefaultPrefetch
+	}
+	return s.options.Prefetch
+}
+
+// handleEventsPrefetching processes events from the queue like handleEvents, fetching the
+// full transactions of up to window events at once: the one being handled, window-2 waiting
+// in the buffer and one waiting to enter it. Events are still handled one at a time, in the
+// order they arrived.
+func (s *Subscription) handleEventsPrefetching(window int) {
+	ahead := make(chan *pubEvent, window-2)
+	go func() {
+		defer close(ahead)
+		for event := range s.eventQueue.Channel() {
+			s.startPrefetch(event)
+			ahead <- event
+		}
+	}()
+
+	for event := range ahead {
+		s.processEvent(event)
+		s.eventQueue.Done()
+	}
+}
+
+// startPrefetch starts fetching the full transaction of a main or mempool event that only
+// carries the transaction ID
+func (s *Subscription) startPrefetch(event *pubEvent) {
+	if event.Channel != "main" && event.Channel != "mempool" || s.stale(event) {
+		return
+	}
+	tx := &models.TransactionResponse{}
+	if err := proto.Unmarshal(event.Data, tx); err != nil || len(tx.Transaction) > 0 {
+		return // decode errors are reported when the event is handled
+	}
+
+	ctx, endEvent := s.startEvent(event.Channel, tx)
+	p := &prefetch{ctx: ctx, endEvent: endEvent, done: make(chan struct{})}
+	event.prefetch = p
+	go func() {
+		defer close(p.done)
+		p.tx, p.err = s.client.GetTransaction(ctx, tx.Id)
+	}()
+}
+
+// beginEvent starts tracing an event, unless that started when its transaction was prefetched
+func (s *Subscription) beginEvent(event *pubEvent, tx *models.TransactionResponse) (context.Context, func(err error)) {
+	if event.prefetch != nil {
+		return event.prefetch.ctx, event.prefetch.endEvent
+	}
+	return s.startEvent(event.Channel, tx)
+}
+
+// fetchTransaction returns the full transaction of an event, waiting for its prefetch if any
+func (s *Subscription) fetchTransaction(ctx context.Context, event *pubEvent, txID string) (*models.Transaction, error) {
+	if p := event.prefetch; p != nil {
+		<-p.done
+		return p.tx, p.err
+	}
+	return s.client.GetTransaction(ctx, txID)
+}
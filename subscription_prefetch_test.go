@@ -0,0 +1,94 @@
+package junglebus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSubscription_Prefetch(t *testing.T) {
+	// Earlier transactions take longer to fetch, so fetching ahead completes out of order
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		id := strings.TrimPrefix(r.URL.Path, "/v1/transaction/get/")
+		n, _ := strconv.Atoi(strings.TrimPrefix(id, "tx"))
+		time.Sleep(time.Duration(10-n) * 5 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `{"id":%q,"transaction":"AQI="}`, id)
+	}))
+	defer ts.Close()
+
+	run := func(t *testing.T, prefetch int) (received []string, concurrent int) {
+		client, err := New(WithHTTPClient(ts.URL, http.DefaultClient), WithMaxConcurrentRequests(8))
+		require.NoError(t, err)
+		sub := &Subscription{
+			EventHandler: EventHandler{
+				OnTransaction: func(tx *models.TransactionResponse) {
+					assert.Equal(t, []byte{1, 2}, tx.Transaction)
+					received = append(received, tx.Id)
+				},
+				OnStatus: func(status *models.ControlResponse) {
+					received = append(received, status.Status)
+				},
+			},
+			client:     client,
+			eventQueue: newEventQueue(100),
+			position:   newPosition(0, 0),
+			options:    &SubscribeOptions{Prefetch: prefetch},
+		}
+
+		for i := 0; i < 10; i++ {
+			data, err := proto.Marshal(&models.TransactionResponse{Id: fmt.Sprintf("tx%d", i), BlockHeight: 100})
+			require.NoError(t, err)
+			sub.addToQueue(&pubEvent{Channel: "main", Data: data})
+			if i == 4 {
+				data, err = proto.Marshal(&models.ControlResponse{StatusCode: uint32(SubscriptionPageDone), Status: "page"})
+				require.NoError(t, err)
+				sub.addToQueue(&pubEvent{Channel: "control", Data: data})
+			}
+		}
+		sub.eventQueue.Close()
+		sub.handleEvents()
+		sub.eventQueue.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		concurrent, maxInFlight = maxInFlight, 0
+		return received, concurrent
+	}
+
+	want := []string{"tx0", "tx1", "tx2", "tx3", "tx4", "page", "tx5", "tx6", "tx7", "tx8", "tx9"}
+
+	t.Run("fetches ahead and delivers in order", func(t *testing.T) {
+		received, concurrent := run(t, 4)
+		assert.Equal(t, want, received)
+		assert.Greater(t, concurrent, 1)
+		assert.LessOrEqual(t, concurrent, 4, "bounded by the window")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		received, concurrent := run(t, -1)
+		assert.Equal(t, want, received)
+		assert.Equal(t, 1, concurrent)
+	})
+}
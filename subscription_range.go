@@ -0,0 +1,29 @@
+package junglebus
+
+import (
+	"log/slog"
+)
+
+// Completed reports whether the subscription stopped because it reached
+// SubscribeOptions.ToBlock. Done is closed once it has unsubscribed.
+func (s *Subscription) Completed() bool {
+	return s.completed.Load()
+}
+
+// checkComplete completes the subscription when the block it finished is its last one
+func (s *Subscription) checkComplete(block uint32) {
+	if s.options == nil || s.options.ToBlock == 0 || block < s.options.ToBlock {
+		return
+	}
+	if !s.completed.CompareAndSwap(false, true) {
+		return
+	}
+	s.log().Info("completed", slog.Uint64("to_block", uint64(s.options.ToBlock)))
+
+	// Unsubscribe waits for the event being handled, which is this one
+	go func() {
+		if err := s.Unsubscribe(); err != nil {
+			s.log().Warn("unsubscribe after completion", slog.Any("error", err))
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package junglebus
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSubscription_ToBlock(t *testing.T) {
+	var received []string
+	sub := &Subscription{
+		EventHandler: EventHandler{
+			OnTransaction: func(tx *models.TransactionResponse) {
+				received = append(received, tx.Id)
+			},
+			OnStatus: func(status *models.ControlResponse) {
+				received = append(received, fmt.Sprintf("done %d", status.Block))
+			},
+		},
+		state:      stateActive,
+		options:    &SubscribeOptions{LiteMode: true, ToBlock: 101},
+		position:   newPosition(100, 0),
+		eventQueue: newEventQueue(10),
+		stats:      newSubscriptionStats(),
+		done:       make(chan struct{}),
+	}
+
+	for block := uint32(100); block <= 102; block++ {
+		data, err := proto.Marshal(&models.TransactionResponse{Id: fmt.Sprintf("tx%d", block), BlockHeight: block})
+		require.NoError(t, err)
+		sub.addToQueue(&pubEvent{Channel: "main", Data: data})
+		data, err = proto.Marshal(&models.ControlResponse{StatusCode: uint32(SubscriptionBlockDone), Block: block})
+		require.NoError(t, err)
+		sub.addToQueue(&pubEvent{Channel: "control", Data: data})
+	}
+	go sub.handleEvents()
+
+	select {
+	case <-sub.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscription did not complete")
+	}
+	assert.Equal(t, []string{"tx100", "done 100", "tx101", "done 101"}, received)
+	assert.True(t, sub.Completed())
+	require.NoError(t, sub.Err())
+	assert.Equal(t, "closed", sub.State())
+
+	t.Run("before from block", func(t *testing.T) {
+		client, err := New(WithToken("token"))
+		require.NoError(t, err)
+		_, err = client.SubscribeWithQueue(context.Background(), "sub", 100, 0, EventHandler{}, &SubscribeOptions{ToBlock: 99})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,186 @@
+package junglebus
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/centrifugal/centrifuge-go"
+)
+
+// Connection defaults, used for the ConnectionOptions left at zero
+const (
+	DefaultReadTimeout        = 30 * time.Second
+	DefaultWriteTimeout       = 2 * time.Second
+	DefaultHandshakeTimeout   = 30 * time.Second
+	DefaultMaxServerPingDelay = 30 * time.Second
+	DefaultMinReconnectDelay  = 200 * time.Millisecond
+	DefaultMaxReconnectDelay  = 20 * time.Second
+)
+
+// ErrReconnectFailed is the terminal error of a subscription that could not reconnect
+// within ConnectionOptions.MaxReconnectAttempts
+var ErrReconnectFailed = errors.New("subscription reconnect failed")
+
+// ConnectionOptions configures the websocket connection of a subscription and how it is
+// re-established after it was lost
+type ConnectionOptions struct {
+	ReadTimeout        time.Duration // How long to wait for reads (DefaultReadTimeout if 0)
+	WriteTimeout       time.Duration // How long to wait for writes (DefaultWriteTimeout if 0)
+	HandshakeTimeout   time.Duration // How long the websocket handshake may take (DefaultHandshakeTimeout if 0)
+	MaxServerPingDelay time.Duration // How late a server ping may be before reconnecting (DefaultMaxServerPingDelay if 0)
+
+	// MinReconnectDelay is the delay before the first reconnect attempt, doubled after each
+	// failed attempt up to MaxReconnectDelay. Delays are randomized by up to half.
+	MinReconnectDelay time.Duration
+	MaxReconnectDelay time.Duration
+
+	// MaxReconnectAttempts is the number of failed attempts after which the subscription gives
+	// up: Done is closed and Err returns ErrReconnectFailed. Zero retries forever.
+	MaxReconnectAttempts int
+}
+
+// withDefaults returns the options with zero values replaced by the defaults
+func (o ConnectionOptions) withDefaults() ConnectionOptions {
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+	if o.HandshakeTimeout <= 0 {
+		o.HandshakeTimeout = DefaultHandshakeTimeout
+	}
+	if o.MaxServerPingDelay <= 0 {
+		o.MaxServerPingDelay = DefaultMaxServerPingDelay
+	}
+	if o.MinReconnectDelay <= 0 {
+		o.MinReconnectDelay = DefaultMinReconnectDelay
+	}
+	if o.MaxReconnectDelay <= 0 {
+		o.MaxReconnectDelay = DefaultMaxReconnectDelay
+	}
+	o.MaxReconnectDelay = max(o.MaxReconnectDelay, o.MinReconnectDelay)
+	return o
+}
+
+// reconnectDelay returns the randomized delay before a reconnect attempt, counted from 1
+func (o ConnectionOptions) reconnectDelay(attempt int) time.Duration {
+	delay := o.MinReconnectDelay
+	for i := 1; i < attempt && delay < o.MaxReconnectDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.MaxReconnectDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// connection returns the subscription's connection options with defaults applied
+func (s *Subscription) connection() ConnectionOptions {
+	if s.options == nil {
+		return ConnectionOptions{}.withDefaults()
+	}
+	return s.options.Connection.withDefaults()
+}
+
+// Err returns the error that ended the subscription once Done is closed, or nil if it was
+// closed with Unsubscribe
+func (s *Subscription) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// onConnectionLost takes over reconnecting from the websocket client after the connection
+// to the server was lost
+func (s *Subscription) onConnectionLost(c *centrifuge.Client, e centrifuge.ConnectingEvent) {
+	if !s.isCurrent(c) || s.getState() == stateClosed || !s.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+	go s.reconnect(c, fmt.Errorf("connection lost: %s", e.Reason))
+}
+
+// reconnect reconnects c with the subscription's backoff until it is connected, replaced by
+// a failover or the subscription is closed. It fails the subscription after too many attempts.
+func (s *Subscription) reconnect(c *centrifuge.Client, cause error) {
+	defer s.reconnecting.Store(false)
+	conn := s.connection()
+
+	for attempt := 1; ; attempt++ {
+		// Stop the client's own reconnect timer, the delay is ours
+		_ = c.Disconnect()
+		if conn.MaxReconnectAttempts > 0 && attempt > conn.MaxReconnectAttempts {
+			s.fail(fmt.Errorf("%w after %d attempts: %w", ErrReconnectFailed, conn.MaxReconnectAttempts, cause))
+			return
+		}
+
+		delay := conn.reconnectDelay(attempt)
+		s.log().Info("reconnecting", slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", cause))
+		if s.EventHandler.OnReconnecting != nil {
+			s.EventHandler.OnReconnecting(attempt, delay, cause)
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if !s.isCurrent(c) || s.getState() == stateClosed {
+			return
+		}
+		if cause = s.attemptConnect(c); cause == nil || !s.isCurrent(c) {
+			return
+		}
+	}
+}
+
+// attemptConnect connects c and waits until it is connected or the attempt failed
+func (s *Subscription) attemptConnect(c *centrifuge.Client) error {
+	result := make(chan error, 1)
+	s.mu.Lock()
+	s.attempt = result
+	s.mu.Unlock()
+
+	if err := c.Connect(); err != nil {
+		s.endAttempt(err)
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// endAttempt passes the outcome of the reconnect attempt in progress, if any, to attemptConnect
+func (s *Subscription) endAttempt(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attempt != nil {
+		s.attempt <- err
+		s.attempt = nil
+	}
+}
+
+// isConnectError reports whether a websocket client error means a connect attempt failed
+func isConnectError(err error) bool {
+	var transportErr centrifuge.TransportError
+	var connectErr centrifuge.ConnectError
+	var refreshErr centrifuge.RefreshError
+	return errors.As(err, &transportErr) || errors.As(err, &connectErr) || errors.As(err, &refreshErr)
+}
+
+// fail closes the subscription with a terminal error, returned by Err once Done is closed
+func (s *Subscription) fail(err error) {
+	s.mu.Lock()
+	if s.state == stateClosed {
+		s.mu.Unlock()
+		return
+	}
+	s.err = err
+	s.mu.Unlock()
+
+	s.reportError(err)
+	_ = s.Unsubscribe()
+}
@@ -0,0 +1,108 @@
+package junglebus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/internal/centrifugetest"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription_Reconnect(t *testing.T) {
+	ctx := context.Background()
+	subscribe := func(t *testing.T, ts *httptest.Server, handler EventHandler, maxAttempts int) *Subscription {
+		client, err := New(WithHTTPClient(ts.Listener.Addr().String(), http.DefaultClient), WithSSL(false), WithToken("token"))
+		require.NoError(t, err)
+		handler.OnTransaction = func(*models.TransactionResponse) {}
+		sub, err := client.SubscribeWithQueue(ctx, "sub", 100, 0, handler, &SubscribeOptions{
+			ChainTipInterval: time.Hour,
+			Connection: ConnectionOptions{
+				MinReconnectDelay:    10 * time.Millisecond,
+				MaxReconnectDelay:    20 * time.Millisecond,
+				MaxReconnectAttempts: maxAttempts,
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = sub.Unsubscribe() })
+		return sub
+	}
+
+	t.Run("reconnects with backoff", func(t *testing.T) {
+		ts := centrifugetest.NewServer(t, func(n int) centrifugetest.Mode {
+			return []centrifugetest.Mode{centrifugetest.Drop, centrifugetest.Refuse, centrifugetest.Stay}[min(n, 3)-1]
+		})
+		var mu sync.Mutex
+		var attempts []int
+		reconnected := make(chan [2]uint64, 1)
+		sub := subscribe(t, ts, EventHandler{
+			OnReconnecting: func(attempt int, delay time.Duration, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts = append(attempts, attempt)
+				assert.LessOrEqual(t, delay, 20*time.Millisecond)
+				assert.Error(t, err)
+			},
+			OnReconnected: func(block uint32, page uint64) {
+				reconnected <- [2]uint64{uint64(block), page}
+			},
+		}, 5)
+
+		select {
+		case position := <-reconnected:
+			assert.Equal(t, [2]uint64{100, 0}, position)
+		case <-time.After(5 * time.Second):
+			t.Fatal("OnReconnected was not called")
+		}
+		mu.Lock()
+		assert.Equal(t, []int{1, 2}, attempts)
+		mu.Unlock()
+		assert.Equal(t, uint64(1), sub.Stats().Reconnects)
+
+		require.NoError(t, sub.Unsubscribe())
+		assert.NoError(t, sub.Err())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		ts := centrifugetest.NewServer(t, func(n int) centrifugetest.Mode {
+			if n == 1 {
+				return centrifugetest.Drop
+			}
+			return centrifugetest.Refuse
+		})
+		var attempts int32
+		sub := subscribe(t, ts, EventHandler{
+			OnReconnecting: func(int, time.Duration, error) {
+				atomic.AddInt32(&attempts, 1)
+			},
+		}, 2)
+
+		select {
+		case <-sub.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("the subscription was not closed")
+		}
+		require.ErrorIs(t, sub.Err(), ErrReconnectFailed)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+		assert.Equal(t, "closed", sub.State())
+	})
+}
+
+func TestConnectionOptions(t *testing.T) {
+	conn := ConnectionOptions{MinReconnectDelay: time.Second, MaxReconnectDelay: 4 * time.Second}.withDefaults()
+	assert.Equal(t, DefaultReadTimeout, conn.ReadTimeout)
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		delay := conn.reconnectDelay(attempt + 1)
+		assert.GreaterOrEqual(t, delay, want/2)
+		assert.LessOrEqual(t, delay, want)
+	}
+
+	conn = ConnectionOptions{MinReconnectDelay: time.Minute}.withDefaults()
+	assert.Equal(t, time.Minute, conn.MaxReconnectDelay, "never below the minimum")
+}
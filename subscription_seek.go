@@ -0,0 +1,133 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrSubscriptionClosed is returned when seeking a subscription that was closed
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// Seek moves the subscription to a block and page, for instance to reprocess a range after a
+// handler bug. The main channel is replaced by one starting at the new position; events already
+// queued from the old position are discarded, while mempool and control events keep flowing.
+// Seek returns once the events queued before it were handled, after which only events from the
+// new position are delivered. If the main channel cannot be replaced, Seek returns the error and
+// the subscription carries on from where it was.
+func (s *Subscription) Seek(ctx context.Context, block uint32, page uint64) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if !s.EventHandler.handlesMain() {
+		return errors.New("subscription has no main channel")
+	}
+
+	// Wait for a failover or stall recovery recreating the channels
+	for !s.switching.CompareAndSwap(false, true) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	marker, err := s.seek(block, page)
+	s.switching.Store(false)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-marker:
+		return nil
+	case <-s.done:
+		return ErrSubscriptionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// seek moves the position, replaces the main channel and queues the marker closed once the
+// events queued before it were handled. It is called with switching held, so a reconnect
+// does not move the main channel back to the old position. If the main channel cannot be
+// replaced, the old channel, generation and position are kept.
+func (s *Subscription) seek(block uint32, page uint64) (chan struct{}, error) {
+	name := fmt.Sprintf("%s:%s:%d:%d", s.getSubType(), s.SubscriptionID, block, page)
+	s.mu.Lock()
+	if s.state == stateClosed {
+		s.mu.Unlock()
+		return nil, ErrSubscriptionClosed
+	}
+	channels, oldName, oldGen := s.channels, s.mainChannelName, s.mainGen.Load()
+	oldBlock, oldPage := s.position.Get()
+	// Events from the old main channel are discarded from here on, and control events that
+	// arrived before no longer move the position
+	seeking := make(chan struct{})
+	s.seeking = seeking
+	s.mainChannelName = name
+	s.mainGen.Add(1)
+	s.position.Update(block, page)
+	s.mu.Unlock()
+
+	s.log().Info("seeking", slog.Uint64("block", uint64(block)), slog.Uint64("page", page))
+
+	err := channels.ReplaceSubscription(oldName, name, s.mainHandler())
+	s.mu.Lock()
+	if err != nil {
+		s.mainChannelName = oldName
+		s.mainGen.Store(oldGen)
+		s.position.Update(oldBlock, oldPage)
+	}
+	s.seeking = nil
+	s.mu.Unlock()
+	close(seeking)
+	if err != nil {
+		return nil, fmt.Errorf("seek: %w", err)
+	}
+
+	marker := make(chan struct{})
+	if !s.eventQueue.SendBlocking(&pubEvent{Channel: "seek", seek: marker}) {
+		return nil, ErrSubscriptionClosed
+	}
+	return marker, nil
+}
+
+// skipEvent handles the events that are not passed to handlers: it checks the lag when the
+// chain tip was polled, signals Seek when its marker is reached, and discards events from a
+// main channel replaced by Seek as well as those after the subscription completed
+func (s *Subscription) skipEvent(event *pubEvent) bool {
+	if event.Channel == "tip" {
+		s.checkLag()
+		return true
+	}
+	if event.seek != nil {
+		s.checkLag()
+		close(event.seek)
+		return true
+	}
+	if !s.stale(event) && !s.completed.Load() {
+		return false
+	}
+	if event.prefetch != nil {
+		event.prefetch.endEvent(nil)
+	}
+	return true
+}
+
+// stale reports whether an event was received on a main channel since replaced by Seek.
+// While a Seek is replacing the main channel it waits for the outcome, as a failed Seek
+// keeps the old channel.
+func (s *Subscription) stale(event *pubEvent) bool {
+	if event.Channel != "main" {
+		return false
+	}
+	s.mu.RLock()
+	seeking := s.seeking
+	s.mu.RUnlock()
+	if seeking != nil && event.gen != s.mainGen.Load() {
+		<-seeking
+	}
+	return event.gen != s.mainGen.Load()
+}
@@ -0,0 +1,133 @@
+package junglebus
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/centrifugal/centrifuge-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSubscription_Seek(t *testing.T) {
+	// The websocket client is never connected, channels are only created and subscribed
+	c := centrifuge.NewProtobufClient("ws://127.0.0.1:0/connection/websocket", centrifuge.Config{})
+	defer c.Close()
+
+	var mu sync.Mutex
+	var received []string
+	entered, release := make(chan struct{}), make(chan struct{})
+	sub := &Subscription{
+		SubscriptionID: "sub",
+		EventHandler: EventHandler{
+			OnTransaction: func(tx *models.TransactionResponse) {
+				if tx.Id == "tx1" {
+					close(entered)
+					<-release
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				received = append(received, tx.Id)
+			},
+			OnStatus: func(status *models.ControlResponse) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = append(received, status.Status)
+			},
+		},
+		state:            stateActive,
+		options:          &SubscribeOptions{LiteMode: true},
+		position:         newPosition(100, 0),
+		centrifugeClient: c,
+		channels:         newChannelManager(c),
+		eventQueue:       newEventQueue(10),
+		stats:            newSubscriptionStats(),
+	}
+	require.NoError(t, sub.setupChannels())
+	require.NoError(t, sub.channels.SubscribeAll())
+	go sub.handleEvents()
+	defer sub.eventQueue.Close()
+
+	publish := func(channel string, msg proto.Message) {
+		data, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		sub.addToQueue(&pubEvent{Channel: channel, Data: data, gen: sub.mainGen.Load()})
+	}
+	publish("main", &models.TransactionResponse{Id: "tx1", BlockHeight: 100})
+	publish("main", &models.TransactionResponse{Id: "tx2", BlockHeight: 100})
+	publish("control", &models.ControlResponse{StatusCode: uint32(SubscriptionPageDone), Status: "page", Block: 100, Transactions: 7})
+
+	<-entered // tx1 is being handled
+	seeked := make(chan error, 1)
+	go func() {
+		seeked <- sub.Seek(context.Background(), 50, 2)
+	}()
+	require.Eventually(t, func() bool {
+		return sub.eventQueue.Len() == 3 // the marker is queued
+	}, 5*time.Second, time.Millisecond)
+
+	// A reconnect before the marker is reached keeps the main channel at the new position,
+	// and a server-side publication on the old main channel is discarded
+	require.NoError(t, sub.updateMainChannelPosition())
+	names := sub.channels.Names()
+	sort.Strings(names)
+	assert.Equal(t, []string{"lite:sub:50:2", "lite:sub:control"}, names)
+	data, err := proto.Marshal(&models.TransactionResponse{Id: "tx9", BlockHeight: 100})
+	require.NoError(t, err)
+	sub.addToQueue(&pubEvent{Channel: "main", Data: data, gen: sub.mainGenFor("lite:sub:100:0")})
+	close(release)
+
+	select {
+	case err := <-seeked:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Seek did not return")
+	}
+	block, page := sub.Position()
+	assert.Equal(t, uint32(50), block)
+	assert.Equal(t, uint64(2), page, "the page done queued before the seek does not move the position")
+
+	publish("main", &models.TransactionResponse{Id: "tx3", BlockHeight: 50})
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"tx1", "page", "tx3"}, received, "queued events from the old position are discarded")
+
+	t.Run("replace fails", func(t *testing.T) {
+		// The channel name is taken, so the new main channel cannot be created
+		_, err := c.NewSubscription("lite:sub:60:0")
+		require.NoError(t, err)
+		block, page := sub.Position()
+		require.Error(t, sub.Seek(context.Background(), 60, 0))
+
+		names := sub.channels.Names()
+		sort.Strings(names)
+		assert.Equal(t, []string{"lite:sub:50:2", "lite:sub:control"}, names, "the old main channel is kept")
+		gotBlock, gotPage := sub.Position()
+		assert.Equal(t, block, gotBlock)
+		assert.Equal(t, page, gotPage)
+
+		data, err := proto.Marshal(&models.TransactionResponse{Id: "tx4", BlockHeight: 50})
+		require.NoError(t, err)
+		sub.addToQueue(&pubEvent{Channel: "main", Data: data, gen: sub.mainGenFor("lite:sub:50:2")})
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received) == 4 && received[3] == "tx4"
+		}, 5*time.Second, time.Millisecond, "events from the old main channel are still delivered")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require.Error(t, sub.Seek(getNilContext(), 1, 0))
+		require.Error(t, (&Subscription{}).Seek(context.Background(), 1, 0), "no main channel")
+		sub.setState(stateClosed)
+		require.ErrorIs(t, sub.Seek(context.Background(), 1, 0), ErrSubscriptionClosed)
+	})
+}
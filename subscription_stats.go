@@ -0,0 +1,358 @@
+package junglebus
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultChainTipInterval is how often a subscription polls the chain tip to compute its lag
+const DefaultChainTipInterval = 30 * time.Second
+
+const (
+	// rateWindow is the number of one-second buckets used to compute events/sec
+	rateWindow = 10
+	// latencySamples is the number of recent handler latencies kept for percentiles
+	latencySamples = 1024
+)
+
+// LatencyPercentiles contains handler latency percentiles over the most recent events
+type LatencyPercentiles struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// SubscriptionStats is a point-in-time snapshot of a subscription's progress and throughput
+type SubscriptionStats struct {
+	Block           uint32               // Current block being processed
+	Page            uint64               // Current page within the block
+	ChainTip        uint32               // Last known chain tip height (0 if not yet polled)
+	LagBlocks       uint32               // Number of blocks behind the chain tip
+	LagSeconds      float64              // Chain time between the last processed block and the tip
+	EventsPerSecond map[string]float64   // Recent events/sec keyed by channel (control, main, mempool)
+	QueueDepth      int                  // Number of events waiting in the queue
+	HandlerLatency  LatencyPercentiles   // Time spent processing events, including handlers
+	Reconnects      uint64               // Number of times the connection was re-established
+	Failovers       uint64               // Number of times the subscription moved to another server
+	StallRecoveries uint64               // Number of times the channels were recreated after a stall
+	LastEventAt     map[string]time.Time // When the last event arrived, keyed by channel
+	Server          string               // Server the subscription is connected to
+	LastError       error                // Most recent error reported to OnError or by the connection
+	LastErrorAt     time.Time            // When LastError was recorded
+}
+
+// rateCounter counts events in one-second buckets over a sliding window
+type rateCounter struct {
+	buckets [rateWindow]uint64
+	stamps  [rateWindow]int64
+}
+
+// add records one event at the given time
+func (r *rateCounter) add(now time.Time) {
+	sec := now.Unix()
+	i := sec % rateWindow
+	if r.stamps[i] != sec {
+		r.stamps[i] = sec
+		r.buckets[i] = 0
+	}
+	r.buckets[i]++
+}
+
+// rate returns the average events per second over the completed buckets in the window
+func (r *rateCounter) rate(now time.Time) float64 {
+	sec := now.Unix()
+	var total uint64
+	for i := range r.buckets {
+		if age := sec - r.stamps[i]; age > 0 && age <= rateWindow {
+			total += r.buckets[i]
+		}
+	}
+	return float64(total) / rateWindow
+}
+
+// subscriptionStats collects the metrics behind Subscription.Stats
+type subscriptionStats struct {
+	mu            sync.Mutex
+	chainTip      uint32
+	chainTipTime  uint32
+	blockTime     uint32
+	lagBlocks     uint32
+	rates         map[string]*rateCounter
+	latencies     [latencySamples]time.Duration
+	latencyCount  int
+	reconnects    uint64
+	failovers     uint64
+	stalls        uint64
+	lastEvent     map[string]time.Time
+	lastError     error
+	lastErrorAt   time.Time
+	lastLagBlocks uint32
+	lagReported   bool
+}
+
+// newSubscriptionStats creates an empty stats collector
+func newSubscriptionStats() *subscriptionStats {
+	return &subscriptionStats{
+		rates:     make(map[string]*rateCounter),
+		lastEvent: make(map[string]time.Time),
+	}
+}
+
+// recordEvent records a processed event on a channel and how long it took
+func (st *subscriptionStats) recordEvent(channel string, latency time.Duration) {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	rc, ok := st.rates[channel]
+	if !ok {
+		rc = &rateCounter{}
+		st.rates[channel] = rc
+	}
+	rc.add(time.Now())
+
+	st.latencies[st.latencyCount%latencySamples] = latency
+	st.latencyCount++
+}
+
+// recordArrival records when an event arrived on a channel, before it is queued
+func (st *subscriptionStats) recordArrival(channel string, at time.Time) {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lastEvent[channel] = at
+}
+
+// lastArrival returns when the most recent event arrived on any of the channels
+func (st *subscriptionStats) lastArrival(channels ...string) time.Time {
+	var last time.Time
+	if st == nil {
+		return last
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, channel := range channels {
+		if at := st.lastEvent[channel]; at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// recordBlockTime records the block time of the most recently processed block transaction
+func (st *subscriptionStats) recordBlockTime(blockTime uint32) {
+	if st == nil || blockTime == 0 {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.blockTime = blockTime
+}
+
+// recordChainTip records the latest chain tip height and time
+func (st *subscriptionStats) recordChainTip(height, blockTime uint32) {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.chainTip = height
+	st.chainTipTime = blockTime
+}
+
+// lastChainTip returns the last recorded chain tip height, 0 if not yet polled
+func (st *subscriptionStats) lastChainTip() uint32 {
+	if st == nil {
+		return 0
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.chainTip
+}
+
+// recordReconnect increments the reconnect counter
+func (st *subscriptionStats) recordReconnect() {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.reconnects++
+}
+
+// recordFailover increments the failover counter
+func (st *subscriptionStats) recordFailover() {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.failovers++
+}
+
+// recordStallRecovery increments the stall recovery counter
+func (st *subscriptionStats) recordStallRecovery() {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.stalls++
+}
+
+// recordError stores the most recent error
+func (st *subscriptionStats) recordError(err error) {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lastError = err
+	st.lastErrorAt = time.Now()
+}
+
+// updateLag recomputes the lag for the given block and reports whether it changed
+// since the last time it was reported
+func (st *subscriptionStats) updateLag(block uint32) bool {
+	if st == nil {
+		return false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.chainTip == 0 {
+		return false
+	}
+	st.lagBlocks = 0
+	if st.chainTip > block {
+		st.lagBlocks = st.chainTip - block
+	}
+	if st.lagReported && st.lagBlocks == st.lastLagBlocks {
+		return false
+	}
+	st.lagReported = true
+	st.lastLagBlocks = st.lagBlocks
+	return true
+}
+
+// snapshot fills in the stats collected by this collector
+func (st *subscriptionStats) snapshot(stats *SubscriptionStats) {
+	stats.EventsPerSecond = make(map[string]float64)
+	stats.LastEventAt = make(map[string]time.Time)
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	for channel, rc := range st.rates {
+		stats.EventsPerSecond[channel] = rc.rate(now)
+	}
+	for channel, at := range st.lastEvent {
+		stats.LastEventAt[channel] = at
+	}
+
+	stats.ChainTip = st.chainTip
+	if st.chainTip > stats.Block {
+		stats.LagBlocks = st.chainTip - stats.Block
+		if st.chainTipTime > st.blockTime && st.blockTime > 0 {
+			stats.LagSeconds = float64(st.chainTipTime - st.blockTime)
+		}
+	}
+
+	n := st.latencyCount
+	if n > latencySamples {
+		n = latencySamples
+	}
+	if n > 0 {
+		samples := make([]time.Duration, n)
+		copy(samples, st.latencies[:n])
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		stats.HandlerLatency = LatencyPercentiles{
+			P50: percentile(samples, 0.50),
+			P90: percentile(samples, 0.90),
+			P99: percentile(samples, 0.99),
+			Max: samples[n-1],
+		}
+	}
+
+	stats.Reconnects = st.reconnects
+	stats.Failovers = st.failovers
+	stats.StallRecoveries = st.stalls
+	stats.LastError = st.lastError
+	stats.LastErrorAt = st.lastErrorAt
+}
+
+// percentile returns the p-th percentile of a sorted, non-empty slice
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+// Stats returns a snapshot of the subscription's position, lag and throughput
+func (s *Subscription) Stats() SubscriptionStats {
+	stats := SubscriptionStats{}
+	stats.Block, stats.Page = s.Position()
+	stats.Server = s.Server()
+	if s.eventQueue != nil {
+		stats.QueueDepth = s.eventQueue.Len()
+	}
+	s.stats.snapshot(&stats)
+	return stats
+}
+
+// reportError records the error in the stats and passes it to the OnError handler
+func (s *Subscription) reportError(err error) {
+	s.stats.recordError(err)
+	s.log().Warn("subscription error", slog.Any("error", err))
+	if s.EventHandler.OnError != nil {
+		s.EventHandler.OnError(err)
+	}
+}
+
+// checkLag recomputes the lag and calls OnLagChanged if the number of blocks behind changed
+func (s *Subscription) checkLag() {
+	if !s.stats.updateLag(s.position.GetBlock()) {
+		return
+	}
+	if s.EventHandler.OnLagChanged != nil {
+		s.EventHandler.OnLagChanged(s.Stats())
+	}
+}
+
+// pollChainTip periodically fetches the chain tip until the subscription context is done
+func (s *Subscription) pollChainTip(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultChainTipInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if tip, err := s.client.GetChainTip(ctx); err == nil && tip != nil {
+			s.stats.recordChainTip(tip.Height, tip.Time)
+			// The lag is checked on the event goroutine, which calls the other handlers. With
+			// the queue full it is checked once the next block is done instead.
+			if s.eventQueue != nil {
+				s.eventQueue.Send(&pubEvent{Channel: "tip"})
+			}
+		} else if err != nil && ctx.Err() == nil {
+			s.log().Warn("poll chain tip", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
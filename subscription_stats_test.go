@@ -0,0 +1,187 @@
+package junglebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRateCounter(t *testing.T) {
+	rc := &rateCounter{}
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 20; i++ {
+		rc.add(now)
+	}
+	// The current second is not complete yet
+	assert.Equal(t, float64(0), rc.rate(now))
+	assert.Equal(t, float64(2), rc.rate(now.Add(time.Second)))
+
+	// Buckets older than the window are ignored
+	assert.Equal(t, float64(0), rc.rate(now.Add((rateWindow+1)*time.Second)))
+}
+
+func TestSubscriptionStats_Snapshot(t *testing.T) {
+	st := newSubscriptionStats()
+	for i := 1; i <= 100; i++ {
+		st.recordEvent("main", time.Duration(i)*time.Millisecond)
+	}
+	st.recordChainTip(110, 2000)
+	st.recordBlockTime(1400)
+	st.recordReconnect()
+	st.recordError(errors.New("boom"))
+
+	stats := SubscriptionStats{Block: 100}
+	st.snapshot(&stats)
+
+	assert.Equal(t, uint32(110), stats.ChainTip)
+	assert.Equal(t, uint32(10), stats.LagBlocks)
+	assert.Equal(t, float64(600), stats.LagSeconds)
+	assert.Contains(t, stats.EventsPerSecond, "main")
+	assert.Equal(t, 50*time.Millisecond, stats.HandlerLatency.P50)
+	assert.Equal(t, 90*time.Millisecond, stats.HandlerLatency.P90)
+	assert.Equal(t, 99*time.Millisecond, stats.HandlerLatency.P99)
+	assert.Equal(t, 100*time.Millisecond, stats.HandlerLatency.Max)
+	assert.Equal(t, uint64(1), stats.Reconnects)
+	assert.EqualError(t, stats.LastError, "boom")
+	assert.False(t, stats.LastErrorAt.IsZero())
+}
+
+func TestSubscriptionStats_UpdateLag(t *testing.T) {
+	st := newSubscriptionStats()
+
+	// No chain tip known yet
+	assert.False(t, st.updateLag(100))
+
+	st.recordChainTip(110, 0)
+	assert.True(t, st.updateLag(100))
+	assert.False(t, st.updateLag(100))
+	assert.True(t, st.updateLag(105))
+	assert.True(t, st.updateLag(111))
+	assert.False(t, st.updateLag(112))
+}
+
+func TestSubscription_Stats(t *testing.T) {
+	t.Run("nil stats collector", func(t *testing.T) {
+		sub := &Subscription{
+			eventQueue: newEventQueue(10),
+			position:   newPosition(5, 2),
+		}
+		stats := sub.Stats()
+		assert.Equal(t, uint32(5), stats.Block)
+		assert.Equal(t, uint64(2), stats.Page)
+		assert.NotNil(t, stats.EventsPerSecond)
+	})
+
+	t.Run("events, errors and lag", func(t *testing.T) {
+		var lagStats []SubscriptionStats
+		var errs []error
+		sub := &Subscription{
+			EventHandler: EventHandler{
+				OnStatus: func(_ *models.ControlResponse) {},
+				OnError: func(err error) {
+					errs = append(errs, err)
+				},
+				OnLagChanged: func(stats SubscriptionStats) {
+					lagStats = append(lagStats, stats)
+				},
+			},
+			eventQueue: newEventQueue(100),
+			position:   newPosition(100, 0),
+			options:    &SubscribeOptions{},
+			stats:      newSubscriptionStats(),
+		}
+		sub.stats.recordChainTip(102, 0)
+
+		status, err := proto.Marshal(&models.ControlResponse{
+			StatusCode: uint32(SubscriptionBlockDone),
+			Block:      100,
+		})
+		require.NoError(t, err)
+
+		go sub.handleEvents()
+		sub.addToQueue(&pubEvent{Channel: "control", Data: status})
+		sub.addToQueue(&pubEvent{Channel: "control", Data: []byte("invalid")})
+		sub.eventQueue.Close()
+		sub.eventQueue.Wait()
+
+		require.Len(t, lagStats, 1)
+		assert.Equal(t, uint32(1), lagStats[0].LagBlocks)
+		require.Len(t, errs, 1)
+
+		stats := sub.Stats()
+		assert.Equal(t, uint32(101), stats.Block)
+		assert.Equal(t, errs[0], stats.LastError)
+		assert.Contains(t, stats.EventsPerSecond, "control")
+		assert.Positive(t, stats.HandlerLatency.Max)
+	})
+}
+
+func TestSubscription_PollChainTip(t *testing.T) {
+	var failing atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/block_header/tip", func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&models.BlockHeader{Height: 150, Time: 5000})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := New(WithHTTPClient(ts.Listener.Addr().String(), http.DefaultClient), WithSSL(false))
+	require.NoError(t, err)
+
+	lagCh := make(chan SubscriptionStats, 1)
+	sub := &Subscription{
+		EventHandler: EventHandler{
+			OnLagChanged: func(stats SubscriptionStats) {
+				lagCh <- stats
+			},
+		},
+		client:     client,
+		position:   newPosition(100, 0),
+		eventQueue: newEventQueue(10),
+		stats:      newSubscriptionStats(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sub.pollChainTip(ctx, time.Hour)
+
+	// The lag is only checked once the event goroutine handles the tip update
+	require.Eventually(t, func() bool {
+		return sub.eventQueue.Len() == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Empty(t, lagCh)
+	go sub.handleEvents()
+	defer sub.eventQueue.Close()
+
+	select {
+	case stats := <-lagCh:
+		assert.Equal(t, uint32(150), stats.ChainTip)
+		assert.Equal(t, uint32(50), stats.LagBlocks)
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnLagChanged was not called")
+	}
+
+	t.Run("poll errors are not reported", func(t *testing.T) {
+		failing.Store(true)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		sub.pollChainTip(ctx, time.Hour)
+		assert.NoError(t, sub.Stats().LastError)
+		assert.Equal(t, uint32(150), sub.Stats().ChainTip)
+	})
+}
@@ -0,0 +1,103 @@
+package junglebus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// DefaultStallTimeout is how long a subscription behind the chain tip may go without events
+// before its channels are recreated
+const DefaultStallTimeout = 5 * time.Minute
+
+// stallTimeout returns how long the subscription may go without events, or 0 if the watchdog
+// is disabled. Only subscriptions to the main channel advance with the chain.
+func (s *Subscription) stallTimeout() time.Duration {
+	if s.options == nil || s.options.StallTimeout < 0 || !s.EventHandler.handlesMain() {
+		return 0
+	}
+	if s.options.StallTimeout == 0 {
+		return DefaultStallTimeout
+	}
+	return s.options.StallTimeout
+}
+
+// watchStalls checks for a stall every quarter of the timeout until ctx is done, recreating
+// the channels each time one is found
+func (s *Subscription) watchStalls(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if last := s.stats.lastArrival("main", "control"); last.After(since) {
+			since = last
+		}
+		idle := time.Since(since)
+		if idle < timeout {
+			continue
+		}
+		if tip, stalled := s.behindChainTip(); stalled {
+			s.resubscribe(idle, tip)
+			since = time.Now()
+		}
+	}
+}
+
+// behindChainTip reports whether the active subscription has events to receive, because the
+// chain tip last polled is past the block it is at and none are waiting in the queue
+func (s *Subscription) behindChainTip() (uint32, bool) {
+	if s.getState() != stateActive || (s.eventQueue != nil && s.eventQueue.Len() > 0) {
+		return 0, false
+	}
+	tip := s.stats.lastChainTip()
+	return tip, tip > s.position.GetBlock()
+}
+
+// resubscribe tears down the channels of a stalled subscription and recreates them from the
+// current block and page on the same connection
+func (s *Subscription) resubscribe(idle time.Duration, tip uint32) {
+	if !s.switching.CompareAndSwap(false, true) {
+		return // failing over, which recreates the channels anyway
+	}
+	defer s.switching.Store(false)
+	if s.getState() == stateClosed {
+		return
+	}
+
+	block, page := s.position.Get()
+	s.log().Warn("subscription stalled, resubscribing", slog.Duration("idle", idle), slog.Uint64("chain_tip", uint64(tip)),
+		slog.Uint64("block", uint64(block)), slog.Uint64("page", page))
+	if s.EventHandler.OnStatus != nil {
+		s.EventHandler.OnStatus(&models.ControlResponse{
+			StatusCode: uint32(StatusSubscribing),
+			Status:     "resubscribing",
+			Message: fmt.Sprintf("No events for %s with the chain tip at %d, resubscribing at block %d, page %d",
+				idle.Round(time.Second), tip, block, page),
+		})
+	}
+
+	s.mu.RLock()
+	channels := s.channels
+	s.mu.RUnlock()
+	if err := channels.UnsubscribeAll(); err != nil {
+		s.reportError(fmt.Errorf("stall recovery: %w", err))
+	}
+	if err := s.setupChannels(); err != nil {
+		s.reportError(fmt.Errorf("stall recovery: %w", err))
+		return
+	}
+	if err := channels.SubscribeAll(); err != nil {
+		s.reportError(fmt.Errorf("stall recovery: subscribe channels: %w", err))
+		return
+	}
+	s.stats.recordStallRecovery()
+}
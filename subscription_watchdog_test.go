@@ -0,0 +1,101 @@
+package junglebus
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/centrifugal/centrifuge-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription_WatchStalls(t *testing.T) {
+	newSub := func(t *testing.T, block uint32) (*Subscription, chan *models.ControlResponse) {
+		// The websocket client is never connected, channels are only created and subscribed
+		c := centrifuge.NewProtobufClient("ws://127.0.0.1:0/connection/websocket", centrifuge.Config{})
+		t.Cleanup(c.Close)
+		statuses := make(chan *models.ControlResponse, 10)
+		sub := &Subscription{
+			SubscriptionID: "sub",
+			EventHandler: EventHandler{
+				OnTransaction: func(*models.TransactionResponse) {},
+				OnStatus: func(status *models.ControlResponse) {
+					statuses <- status
+				},
+			},
+			state:            stateActive,
+			options:          &SubscribeOptions{},
+			position:         newPosition(100, 0),
+			centrifugeClient: c,
+			channels:         newChannelManager(c),
+			eventQueue:       newEventQueue(10),
+			stats:            newSubscriptionStats(),
+		}
+		require.NoError(t, sub.setupChannels())
+		require.NoError(t, sub.channels.SubscribeAll())
+		sub.position.Update(block, 3)
+		sub.stats.recordChainTip(150, 0)
+		return sub, statuses
+	}
+
+	t.Run("resubscribes from the current position", func(t *testing.T) {
+		sub, statuses := newSub(t, 120)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go sub.watchStalls(ctx, 40*time.Millisecond)
+
+		select {
+		case status := <-statuses:
+			assert.Equal(t, "resubscribing", status.Status)
+			assert.Contains(t, status.Message, "chain tip at 150, resubscribing at block 120, page 3")
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stall was not detected")
+		}
+		cancel()
+
+		require.Eventually(t, func() bool {
+			return sub.Stats().StallRecoveries >= 1
+		}, 5*time.Second, 10*time.Millisecond)
+		names := sub.channels.Names()
+		sort.Strings(names)
+		assert.Equal(t, []string{"query:sub:120:3", "query:sub:control"}, names)
+		assert.Equal(t, "query:sub:120:3", sub.mainChannelName)
+	})
+
+	t.Run("not stalled", func(t *testing.T) {
+		// Events keep arriving, then the subscription is at the chain tip
+		sub, statuses := newSub(t, 150)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		go func() {
+			for ctx.Err() == nil {
+				sub.stats.recordArrival("control", time.Now())
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		sub.watchStalls(ctx, 40*time.Millisecond)
+		assert.Empty(t, statuses)
+		assert.Zero(t, sub.Stats().StallRecoveries)
+	})
+
+	t.Run("chain tip not yet polled", func(t *testing.T) {
+		sub, statuses := newSub(t, 120)
+		sub.stats.recordChainTip(0, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		sub.watchStalls(ctx, 40*time.Millisecond)
+		assert.Empty(t, statuses)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sub := &Subscription{EventHandler: EventHandler{OnTransaction: func(*models.TransactionResponse) {}}}
+		sub.options = &SubscribeOptions{}
+		assert.Equal(t, DefaultStallTimeout, sub.stallTimeout())
+		sub.options.StallTimeout = -1
+		assert.Zero(t, sub.stallTimeout())
+		assert.Zero(t, (&Subscription{options: &SubscribeOptions{}}).stallTimeout(), "mempool only")
+	})
+}
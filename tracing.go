@@ -0,0 +1,34 @@
+package junglebus
+
+import (
+	"context"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// EventTracer instruments the processing of subscription transaction events.
+// StartEvent is called once a main or mempool event has been decoded and returns the context
+// used for requests made while handling it (such as fetching the full transaction), together
+// with a function that is called with the outcome once handling has finished.
+// See the otel package for an OpenTelemetry implementation.
+type EventTracer interface {
+	StartEvent(ctx context.Context, subscriptionID string, channel string, tx *models.TransactionResponse) (context.Context, func(err error))
+}
+
+// TransportWrapper wraps a transport service, e.g. to add tracing or recording. The wrapper
+// should implement transports.Unwrapper so optional features of the wrapped transport service,
+// such as server failover and address history streams, keep working.
+type TransportWrapper func(next transports.TransportService) transports.TransportService
+
+// startEvent starts tracing an event with the client event tracer, if any
+func (s *Subscription) startEvent(channel string, tx *models.TransactionResponse) (context.Context, func(err error)) {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.client == nil || s.client.eventTracer == nil {
+		return ctx, func(error) {}
+	}
+	return s.client.eventTracer.StartEvent(ctx, s.SubscriptionID, channel, tx)
+}
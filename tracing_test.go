@@ -0,0 +1,115 @@
+package junglebus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type testEventTracer struct {
+	started []string
+	ended   []error
+}
+
+type tracerCtxKey struct{}
+
+func (t *testEventTracer) StartEvent(ctx context.Context, _ string, channel string, tx *models.TransactionResponse) (context.Context, func(err error)) {
+	t.started = append(t.started, channel+":"+tx.Id)
+	return context.WithValue(ctx, tracerCtxKey{}, tx.Id), func(err error) {
+		t.ended = append(t.ended, err)
+	}
+}
+
+type wrappedTransport struct {
+	transports.TransportService
+	ctxValues []interface{}
+}
+
+func (w *wrappedTransport) GetTransaction(ctx context.Context, txID string) (*models.Transaction, error) {
+	w.ctxValues = append(w.ctxValues, ctx.Value(tracerCtxKey{}))
+	return &models.Transaction{ID: txID, Transaction: []byte("full-tx")}, nil
+}
+
+type unwrappingTransport struct {
+	wrappedTransport
+}
+
+func (w *unwrappingTransport) Unwrap() transports.TransportService {
+	return w.TransportService
+}
+
+func TestWithTransportWrapper(t *testing.T) {
+	var wrapped *wrappedTransport
+	client, err := New(WithHTTP("test-url"), WithTransportWrapper(func(next transports.TransportService) transports.TransportService {
+		wrapped = &wrappedTransport{TransportService: next}
+		return wrapped
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, wrapped, client.transport)
+	assert.Equal(t, "test-url", client.transport.GetServerURL())
+
+	// Optional features of the wrapped transport are not reachable without Unwrap
+	assert.Nil(t, client.ServerStatus())
+	assert.Equal(t, transports.ConcurrencyStats{}, client.ConcurrencyStats())
+	history, err := client.AddressHistory(context.Background(), "address", nil)
+	require.NoError(t, err)
+	assert.False(t, history.Next())
+	assert.ErrorIs(t, history.Err(), transports.ErrNotSupported)
+
+	client, err = New(WithHTTP("test-url"), WithTransportWrapper(func(next transports.TransportService) transports.TransportService {
+		return &unwrappingTransport{wrappedTransport{TransportService: next}}
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, transports.DefaultMaxConcurrentRequests, client.ConcurrencyStats().Limit)
+
+	// A nil wrapper is ignored
+	client, err = New(WithTransportWrapper(nil))
+	require.NoError(t, err)
+	assert.NotNil(t, client.transport)
+}
+
+func TestSubscription_EventTracer(t *testing.T) {
+	tracer := &testEventTracer{}
+	var wrapped *wrappedTransport
+	client, err := New(
+		WithEventTracer(tracer),
+		WithTransportWrapper(func(next transports.TransportService) transports.TransportService {
+			wrapped = &wrappedTransport{TransportService: next}
+			return wrapped
+		}),
+	)
+	require.NoError(t, err)
+
+	var received []*models.TransactionResponse
+	sub := &Subscription{
+		SubscriptionID: "test-sub",
+		EventHandler: EventHandler{
+			OnTransaction: func(tx *models.TransactionResponse) {
+				received = append(received, tx)
+			},
+		},
+		client:     client,
+		eventQueue: newEventQueue(10),
+		position:   newPosition(0, 0),
+		options:    &SubscribeOptions{},
+	}
+
+	txData, err := proto.Marshal(&models.TransactionResponse{Id: "test-tx", BlockHeight: 10})
+	require.NoError(t, err)
+
+	go sub.handleEvents()
+	sub.addToQueue(&pubEvent{Channel: "main", Data: txData})
+	sub.eventQueue.Close()
+	sub.eventQueue.Wait()
+
+	assert.Equal(t, []string{"main:test-tx"}, tracer.started)
+	assert.Equal(t, []error{nil}, tracer.ended)
+	assert.Equal(t, []interface{}{"test-tx"}, wrapped.ctxValues)
+	require.Len(t, received, 1)
+	assert.Equal(t, []byte("full-tx"), received[0].Transaction)
+}
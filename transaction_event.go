@@ -0,0 +1,104 @@
+package junglebus
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// TransactionEvent is a main or mempool transaction passed to OnTransactionEvent and
+// OnMempoolEvent. In lite mode it has no transaction body, which can be loaded on demand
+// together with its BEEF and merkle proof. Requests are made with the subscription's client
+// and stop when the subscription is closed; successful results are cached.
+// The methods are safe for concurrent use, also after the handler returns.
+type TransactionEvent struct {
+	*models.TransactionResponse
+
+	client *Client
+	subCtx context.Context
+
+	mu    sync.Mutex
+	full  *models.Transaction
+	raw   []byte
+	beef  []byte
+	proof []byte
+}
+
+// newTransactionEvent creates the event for a transaction, bound to the subscription. full is
+// the transaction fetched for the event when not in lite mode, if any.
+func (s *Subscription) newTransactionEvent(tx *models.TransactionResponse, full *models.Transaction) *TransactionEvent {
+	subCtx := s.ctx
+	if subCtx == nil {
+		subCtx = context.Background()
+	}
+	event := &TransactionEvent{TransactionResponse: tx, client: s.client, subCtx: subCtx, full: full}
+	if len(tx.Transaction) > 0 {
+		event.raw = tx.Transaction
+	}
+	return event
+}
+
+// Load returns the full transaction with its index data (addresses, outputs, contexts...)
+func (e *TransactionEvent) Load(ctx context.Context) (*models.Transaction, error) {
+	return loadOnce(ctx, e, &e.full, func(ctx context.Context) (*models.Transaction, error) {
+		return e.client.GetTransaction(ctx, e.Id)
+	})
+}
+
+// Raw returns the raw transaction, from the event if it has the body
+func (e *TransactionEvent) Raw(ctx context.Context) ([]byte, error) {
+	return loadOnce(ctx, e, &e.raw, func(ctx context.Context) ([]byte, error) {
+		return e.client.GetRawTransaction(ctx, e.Id)
+	})
+}
+
+// Beef returns the transaction in BEEF format
+func (e *TransactionEvent) Beef(ctx context.Context) ([]byte, error) {
+	return loadOnce(ctx, e, &e.beef, func(ctx context.Context) ([]byte, error) {
+		return e.client.GetBeef(ctx, e.Id)
+	})
+}
+
+// Proof returns the merkle proof of a mined transaction
+func (e *TransactionEvent) Proof(ctx context.Context) ([]byte, error) {
+	return loadOnce(ctx, e, &e.proof, func(ctx context.Context) ([]byte, error) {
+		return e.client.GetProof(ctx, e.Id)
+	})
+}
+
+// loadOnce returns the cached value, or fetches it with a context that is also cancelled when
+// the subscription is closed. Errors are not cached, so a failed load can be retried.
+func loadOnce[T []byte | *models.Transaction](ctx context.Context, e *TransactionEvent, cached *T, fetch func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if ctx == nil {
+		return zero, errors.New("context cannot be nil")
+	}
+	e.mu.Lock()
+	value := *cached
+	e.mu.Unlock()
+	if value != nil {
+		return value, nil
+	}
+	if e.client == nil {
+		return zero, errors.New("event is not bound to a client")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(e.subCtx, cancel)
+	defer stop()
+
+	// Concurrent loads of the same value are coalesced by the transport
+	value, err := fetch(ctx)
+	if err != nil {
+		return zero, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if *cached == nil {
+		*cached = value
+	}
+	return *cached, nil
+}
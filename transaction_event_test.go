@@ -0,0 +1,112 @@
+package junglebus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTransactionEvent(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/transaction/get/tx1":
+			_, _ = w.Write([]byte(`{"id":"tx1","transaction":"AQI=","addresses":["1Addr"]}`))
+		case "/v1/transaction/get/tx1/bin":
+			_, _ = w.Write([]byte{1, 2})
+		case "/v1/transaction/beef/tx1":
+			_, _ = w.Write([]byte{0xbe, 0xef})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := New(WithHTTPClient(ts.URL, http.DefaultClient))
+	require.NoError(t, err)
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var events []*TransactionEvent
+	sub := &Subscription{
+		EventHandler: EventHandler{
+			OnTransactionEvent: func(event *TransactionEvent) {
+				events = append(events, event)
+			},
+		},
+		client:   client,
+		position: newPosition(0, 0),
+		options:  &SubscribeOptions{LiteMode: true},
+		ctx:      subCtx,
+	}
+	data, err := proto.Marshal(&models.TransactionResponse{Id: "tx1", BlockHeight: 100})
+	require.NoError(t, err)
+	sub.processEvent(&pubEvent{Channel: "main", Data: data})
+	require.Len(t, events, 1)
+	event := events[0]
+	ctx := context.Background()
+
+	assert.Equal(t, "tx1", event.Id)
+	assert.Empty(t, event.Transaction, "lite events have no body")
+
+	t.Run("loads are cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			full, err := event.Load(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1Addr"}, full.Addresses)
+			raw, err := event.Raw(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{1, 2}, raw)
+			beef, err := event.Beef(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{0xbe, 0xef}, beef)
+		}
+		assert.Equal(t, map[string]int{
+			"/v1/transaction/get/tx1":     1,
+			"/v1/transaction/get/tx1/bin": 1,
+			"/v1/transaction/beef/tx1":    1,
+		}, hits)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		_, err := event.Proof(ctx)
+		require.ErrorIs(t, err, transports.ErrNotFound)
+		_, err = event.Proof(ctx)
+		require.ErrorIs(t, err, transports.ErrNotFound)
+		assert.Equal(t, 2, hits["/v1/transaction/proof/tx1/bin"])
+		_, err = event.Proof(getNilContext())
+		require.Error(t, err)
+	})
+
+	t.Run("bound to the subscription", func(t *testing.T) {
+		cancel()
+		_, err := event.Load(ctx)
+		require.NoError(t, err, "cached values are still available")
+		_, err = event.Proof(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("full transaction events", func(t *testing.T) {
+		fullEvent := (&Subscription{client: client}).newTransactionEvent(
+			&models.TransactionResponse{Id: "tx1", Transaction: []byte{1, 2}}, &models.Transaction{ID: "tx1"})
+		raw, err := fullEvent.Raw(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2}, raw)
+		full, err := fullEvent.Load(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "tx1", full.ID)
+		assert.Equal(t, 1, hits["/v1/transaction/get/tx1/bin"], "nothing is fetched again")
+	})
+}
@@ -0,0 +1,237 @@
+package transports
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitFailureThreshold is the number of consecutive failures that open a circuit
+	DefaultCircuitFailureThreshold = 5
+	// DefaultCircuitOpenTimeout is how long a circuit stays open before allowing a trial request
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests immediately with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through to test recovery
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker of the HTTP transport. Each route class
+// (see RouteTemplate) has its own circuit, so a failing endpoint does not block the others.
+// Network errors, 5xx and 429 responses count as failures; cancelled requests do not count.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that open a circuit
+	// (DefaultCircuitFailureThreshold if 0)
+	FailureThreshold int
+
+	// OpenTimeout is how long a circuit stays open before it lets trial requests through
+	// (DefaultCircuitOpenTimeout if 0)
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of trial requests let through while half-open; that many
+	// successes close the circuit and any failure opens it again (1 if 0)
+	HalfOpenRequests int
+
+	// OnStateChange is called when a circuit changes state, e.g. for alerting
+	OnStateChange func(route string, from, to CircuitState)
+}
+
+// circuit is the state of one route class
+type circuit struct {
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	trials    int // trial requests in flight while half-open
+	successes int // successful trial requests while half-open
+	halfOpens int // number of times the circuit went half-open, to ignore stale trials
+}
+
+// circuitBreaker tracks a circuit per route class
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	logger   func() *slog.Logger
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+// newCircuitBreaker creates a circuit breaker, filling in defaults
+func newCircuitBreaker(config CircuitBreakerConfig, logger func() *slog.Logger) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	return &circuitBreaker{
+		config:   config,
+		logger:   logger,
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+}
+
+// noopDone is returned by allow when there is no circuit breaker
+func noopDone(context.Context, *http.Response, error) {}
+
+// allow checks the circuit of a request path. It returns ErrCircuitOpen if the request must
+// fail fast, or a function to call with the outcome of the request.
+func (b *circuitBreaker) allow(path string) (done func(ctx context.Context, resp *http.Response, err error), err error) {
+	if b == nil {
+		return noopDone, nil
+	}
+	route := RouteTemplate(path)
+
+	b.mu.Lock()
+	c, ok := b.circuits[route]
+	if !ok {
+		c = &circuit{}
+		b.circuits[route] = c
+	}
+	from := c.state
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.config.OpenTimeout {
+		c.state, c.trials, c.successes = CircuitHalfOpen, 0, 0
+		c.halfOpens++
+	}
+	trial := -1 // the half-open period the request is a trial in, if any
+	switch c.state {
+	case CircuitOpen:
+		err = fmt.Errorf("%w: %s", ErrCircuitOpen, route)
+	case CircuitHalfOpen:
+		if c.trials < b.config.HalfOpenRequests {
+			c.trials++
+			trial = c.halfOpens
+		} else {
+			err = fmt.Errorf("%w: %s", ErrCircuitOpen, route)
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.changed(route, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, resp *http.Response, err error) {
+		b.record(route, trial, requestOutcome(ctx, resp, err))
+	}, nil
+}
+
+// outcome is the result of a request as seen by the circuit breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+// requestOutcome classifies a finished request
+func requestOutcome(ctx context.Context, resp *http.Response, err error) outcome {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return outcomeIgnored
+	case err != nil:
+		return outcomeFailure
+	case resp.StatusCode >= http.StatusInternalServerError, resp.StatusCode == http.StatusTooManyRequests:
+		return outcomeFailure
+	default:
+		return outcomeSuccess
+	}
+}
+
+// record updates a circuit with the outcome of a request
+func (b *circuitBreaker) record(route string, trial int, result outcome) {
+	b.mu.Lock()
+	c := b.circuits[route]
+	from := c.state
+	switch c.state {
+	case CircuitClosed:
+		switch result {
+		case outcomeSuccess:
+			c.failures = 0
+		case outcomeFailure:
+			c.failures++
+			if c.failures >= b.config.FailureThreshold {
+				c.state, c.openedAt = CircuitOpen, b.now()
+			}
+		}
+	case CircuitHalfOpen:
+		if trial != c.halfOpens {
+			break
+		}
+		c.trials--
+		switch result {
+		case outcomeSuccess:
+			c.successes++
+			if c.successes >= b.config.HalfOpenRequests {
+				c.state, c.failures = CircuitClosed, 0
+			}
+		case outcomeFailure:
+			c.state, c.openedAt = CircuitOpen, b.now()
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.changed(route, from, to)
+}
+
+// changed logs a state change and calls the callback, outside the lock
+func (b *circuitBreaker) changed(route string, from, to CircuitState) {
+	if from == to {
+		return
+	}
+	level := slog.LevelInfo
+	if to == CircuitOpen {
+		level = slog.LevelWarn
+	}
+	b.logger().LogAttrs(context.Background(), level, "circuit state changed",
+		slog.String("route", route), slog.String("from", from.String()), slog.String("to", to.String()))
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(route, from, to)
+	}
+}
+
+// states returns the state of every route class with a circuit
+func (b *circuitBreaker) states() map[string]CircuitState {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make(map[string]CircuitState, len(b.circuits))
+	for route, c := range b.circuits {
+		states[route] = c.state
+	}
+	return states
+}
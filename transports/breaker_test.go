@@ -0,0 +1,114 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportHTTP_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	status := int32(http.StatusBadGateway)
+	var hits int32
+	ts := newCountingServer(t, &status, &hits)
+
+	type change struct {
+		route    string
+		from, to CircuitState
+	}
+	var changes []change
+	service, err := NewTransport(
+		WithHTTP(ts.URL),
+		WithCircuitBreaker(&CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenTimeout:      time.Minute,
+			OnStateChange: func(route string, from, to CircuitState) {
+				changes = append(changes, change{route, from, to})
+			},
+		}),
+	)
+	require.NoError(t, err)
+	transport := service.(*TransportHTTP)
+	now := time.Now()
+	transport.breaker.now = func() time.Time { return now }
+
+	t.Run("opens after the threshold", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err = transport.GetChainTip(ctx)
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrCircuitOpen)
+		}
+		_, err = transport.GetChainTip(ctx)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), atomic.SwapInt32(&hits, 0), "an open circuit fails fast")
+		assert.Equal(t, CircuitOpen, transport.CircuitStates()["/block_header/tip"])
+	})
+
+	t.Run("other routes are not affected", func(t *testing.T) {
+		atomic.StoreInt32(&status, http.StatusOK)
+		_, err = transport.GetBlockHeader(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, CircuitClosed, transport.CircuitStates()["/block_header/get/{block}"])
+		atomic.StoreInt32(&hits, 0)
+	})
+
+	t.Run("reopens when the trial fails", func(t *testing.T) {
+		atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+		now = now.Add(time.Minute)
+		_, err = transport.GetChainTip(ctx)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+		_, err = transport.GetChainTip(ctx)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(1), atomic.SwapInt32(&hits, 0))
+	})
+
+	t.Run("closes when the trial succeeds", func(t *testing.T) {
+		atomic.StoreInt32(&status, http.StatusOK)
+		now = now.Add(time.Minute)
+		_, err = transport.GetChainTip(ctx)
+		require.NoError(t, err)
+		_, err = transport.GetChainTip(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, CircuitClosed, transport.CircuitStates()["/block_header/tip"])
+	})
+
+	route := "/block_header/tip"
+	assert.Equal(t, []change{
+		{route, CircuitClosed, CircuitOpen},
+		{route, CircuitOpen, CircuitHalfOpen},
+		{route, CircuitHalfOpen, CircuitOpen},
+		{route, CircuitOpen, CircuitHalfOpen},
+		{route, CircuitHalfOpen, CircuitClosed},
+	}, changes)
+
+	t.Run("disabled", func(t *testing.T) {
+		transport.SetCircuitBreaker(nil)
+		assert.Nil(t, transport.CircuitStates())
+	})
+}
+
+func TestCircuitBreaker_Outcomes(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}, (&TransportHTTP{}).log)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done, err := b.allow("/transaction/get/abc")
+	require.NoError(t, err)
+	done(context.Background(), &http.Response{StatusCode: http.StatusNotFound}, nil)
+	done, err = b.allow("/transaction/get/abc")
+	require.NoError(t, err, "a 404 is not a failure")
+	done(cancelled, nil, context.Canceled)
+	done, err = b.allow("/transaction/get/def")
+	require.NoError(t, err, "a cancelled request is not a failure")
+	done(context.Background(), &http.Response{StatusCode: http.StatusTooManyRequests}, nil)
+
+	_, err = b.allow("/transaction/get/abc")
+	require.ErrorIs(t, err, ErrCircuitOpen, "routes are grouped by template")
+	assert.Equal(t, map[string]CircuitState{"/transaction/get/{txid}": CircuitOpen}, b.states())
+}
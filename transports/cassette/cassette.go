@@ -0,0 +1,381 @@
+// Package cassette records JungleBus HTTP interactions to fixture files and replays them,
+// so tests can exercise the real transport without a live server.
+//
+//	rec, err := cassette.New("testdata/get_transaction.json", cassette.ModeAuto)
+//	defer rec.Stop()
+//	client, err := junglebus.New(junglebus.WithHTTPClient("https://junglebus.gorillapool.io", rec.Client()))
+//
+// Requests are matched on method, path and query. The token header, login credentials and
+// returned tokens are scrubbed before anything is written to disk.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// Mode selects whether a Recorder talks to the server or serves fixtures
+type Mode int
+
+const (
+	// ModeReplay serves responses from the fixture file and never touches the network
+	ModeReplay Mode = iota
+	// ModeRecord forwards every request to the server and saves the interactions on Stop
+	ModeRecord
+	// ModeAuto replays if the fixture file exists and records otherwise
+	ModeAuto
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModeAuto:
+		return "auto"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrNoInteraction is returned in replay mode when no recorded interaction matches a request
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches request")
+
+// DefaultScrubHeaders are the request headers replaced by transports.RedactedValue when recording
+var DefaultScrubHeaders = []string{"token", "Authorization"}
+
+// DefaultScrubFields are the JSON body fields, in requests and responses, replaced by
+// transports.RedactedValue when recording
+var DefaultScrubFields = []string{
+	transports.FieldUsername, transports.FieldPassword, "token", "private_key",
+}
+
+// Request is the recorded part of an HTTP request
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Response is the recorded part of an HTTP response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the contents of a fixture file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Option configures a Recorder
+type Option func(r *Recorder)
+
+// WithRealTransport sets the round tripper used to reach the server in record mode
+// (http.DefaultTransport by default)
+func WithRealTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.real = rt
+	}
+}
+
+// WithScrubHeaders replaces the list of request headers that are redacted when recording
+func WithScrubHeaders(headers ...string) Option {
+	return func(r *Recorder) {
+		r.scrubHeaders = headers
+	}
+}
+
+// WithScrubFields replaces the list of JSON body fields that are redacted when recording
+func WithScrubFields(fields ...string) Option {
+	return func(r *Recorder) {
+		r.scrubFields = fields
+	}
+}
+
+// WithFilter adds a function that can modify each interaction before it is saved,
+// e.g. to remove secrets from request or response bodies
+func WithFilter(filter func(i *Interaction)) Option {
+	return func(r *Recorder) {
+		r.filters = append(r.filters, filter)
+	}
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions
+type Recorder struct {
+	mu           sync.Mutex
+	path         string
+	mode         Mode
+	real         http.RoundTripper
+	scrubHeaders []string
+	scrubFields  []string
+	filters      []func(i *Interaction)
+	cassette     *Cassette
+	used         []bool
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// New creates a recorder for the fixture file at path. In replay mode the file must exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:         path,
+		mode:         mode,
+		real:         http.DefaultTransport,
+		scrubHeaders: DefaultScrubHeaders,
+		scrubFields:  DefaultScrubFields,
+		cassette:     &Cassette{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+
+	if r.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: load %s: %w", path, err)
+		}
+		if err = json.Unmarshal(data, r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: parse %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode the recorder is running in (never ModeAuto)
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an http.Client using the recorder, for transports.WithHTTPClient
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Transport returns a JungleBus transport for serverURL that goes through the recorder
+func (r *Recorder) Transport(serverURL string, opts ...transports.ClientOps) (transports.TransportService, error) {
+	return transports.NewTransport(append([]transports.ClientOps{
+		transports.WithHTTPClient(serverURL, r.Client()),
+	}, opts...)...)
+}
+
+// Interactions returns the recorded interactions
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+// replay serves the first unused interaction matching the request, or the last matching one
+// if they have all been used, so repeated identical requests keep working
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := normalizeQuery(req)
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if !in.Request.matches(req.Method, req.URL.Path, query) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+	}
+	r.used[match] = true
+
+	in := r.cassette.Interactions[match]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// record forwards the request to the server and keeps a scrubbed copy of the interaction
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  normalizeQuery(req),
+			Header: r.scrub(req.Header),
+			Body:   r.scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       r.scrubBody(respBody),
+		},
+	}
+	for _, filter := range r.filters {
+		filter(in)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// scrub returns a copy of the headers with sensitive values redacted
+func (r *Recorder) scrub(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for _, name := range r.scrubHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, transports.RedactedValue)
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody returns the body with the values of sensitive JSON fields redacted, at any depth.
+// Bodies that are not JSON or have nothing to redact are returned as they are.
+func (r *Recorder) scrubBody(body []byte) []byte {
+	if len(r.scrubFields) == 0 || !json.Valid(body) {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil || !r.scrubValue(v) {
+		return body
+	}
+	scrubbed, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+// scrubValue redacts sensitive fields in a decoded JSON value and reports whether it did
+func (r *Recorder) scrubValue(v interface{}) bool {
+	scrubbed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if r.isScrubField(key) {
+				v[key] = transports.RedactedValue
+				scrubbed = true
+			} else if r.scrubValue(value) {
+				scrubbed = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if r.scrubValue(value) {
+				scrubbed = true
+			}
+		}
+	}
+	return scrubbed
+}
+
+// isScrubField reports whether a JSON field is redacted when recording
+func (r *Recorder) isScrubField(key string) bool {
+	for _, field := range r.scrubFields {
+		if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop saves the recorded interactions in record mode. It does nothing in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o600)
+}
+
+// matches reports whether the recorded request has the given method, path and query
+func (q *Request) matches(method, path, query string) bool {
+	return strings.EqualFold(q.Method, method) && q.Path == path && q.Query == query
+}
+
+// normalizeQuery returns the request query with its parameters sorted
+func normalizeQuery(req *http.Request) string {
+	return req.URL.Query().Encode()
+}
@@ -0,0 +1,171 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, calls *int) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		switch {
+		case r.URL.Path == "/v1/transaction/get/abc":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(models.Transaction{ID: "abc", BlockHeight: 100})
+		case r.URL.Path == "/v1/block_header/list/100" && r.URL.Query().Get("limit") == "2":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]*models.BlockHeader{{Height: 100}, {Height: 101}})
+		case r.URL.Path == "/v1/transaction/get/abc/bin":
+			_, _ = w.Write([]byte{0x01, 0x02, 0x03})
+		case r.URL.Path == "/v1/user/login", r.URL.Path == "/v1/user/refresh-token":
+			_, _ = w.Write([]byte(`{"token":"server-token"}`))
+		case r.URL.Path == "/v1/user/subscription-token":
+			_, _ = w.Write([]byte(`{"token":"sub-token","expires":60}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "cassette.json")
+	ctx := context.Background()
+	calls := 0
+	ts := newTestServer(t, &calls)
+
+	// Record against the test server
+	rec, err := New(path, ModeAuto)
+	require.NoError(t, err)
+	assert.Equal(t, ModeRecord, rec.Mode())
+
+	transport, err := rec.Transport(ts.URL, transports.WithToken("secret-token"))
+	require.NoError(t, err)
+
+	tx, err := transport.GetTransaction(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", tx.ID)
+	headers, err := transport.GetBlockHeaders(ctx, "100", 2)
+	require.NoError(t, err)
+	assert.Len(t, headers, 2)
+	raw, err := transport.GetRawTransaction(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, raw)
+	require.NoError(t, rec.Stop())
+	assert.Equal(t, 3, calls)
+
+	// The token is never written to disk
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.Contains(t, string(data), transports.RedactedValue)
+
+	// Replay without the server
+	ts.Close()
+	replay, err := New(path, ModeAuto)
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, replay.Mode())
+
+	transport, err = replay.Transport(ts.URL)
+	require.NoError(t, err)
+
+	tx, err = transport.GetTransaction(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), tx.BlockHeight)
+	headers, err = transport.GetBlockHeaders(ctx, "100", 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(101), headers[1].Height)
+	raw, err = transport.GetRawTransaction(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, raw)
+
+	// Repeated requests reuse the last match
+	_, err = transport.GetTransaction(ctx, "abc")
+	require.NoError(t, err)
+
+	// Unknown requests and different queries fail
+	_, err = transport.GetTransaction(ctx, "def")
+	require.ErrorIs(t, err, ErrNoInteraction)
+	_, err = transport.GetBlockHeaders(ctx, "100", 3)
+	require.ErrorIs(t, err, ErrNoInteraction)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRecorder_ScrubsCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login.json")
+	ctx := context.Background()
+	calls := 0
+	ts := newTestServer(t, &calls)
+
+	rec, err := New(path, ModeRecord)
+	require.NoError(t, err)
+	transport, err := rec.Transport(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, transport.Login(ctx, "alice", "hunter2"))
+	assert.Equal(t, "server-token", transport.GetToken(), "the caller still gets the real response")
+	_, err = transport.RefreshToken(ctx)
+	require.NoError(t, err)
+	_, err = transport.GetSubscriptionToken(ctx, "sub")
+	require.NoError(t, err)
+	require.NoError(t, rec.Stop())
+
+	// Bodies are saved base64 encoded, so check them decoded from the fixture
+	replay, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	interactions := replay.Interactions()
+	require.Len(t, interactions, 3)
+	for _, in := range interactions {
+		for _, secret := range []string{"alice", "hunter2", "server-token", "sub-token"} {
+			assert.NotContains(t, string(in.Request.Body), secret)
+			assert.NotContains(t, string(in.Response.Body), secret)
+		}
+	}
+	assert.JSONEq(t, `{"username":"[REDACTED]","password":"[REDACTED]"}`, string(interactions[0].Request.Body))
+	assert.JSONEq(t, `{"token":"[REDACTED]","expires":60}`, string(interactions[2].Response.Body))
+	assert.Contains(t, string(interactions[2].Request.Body), `"sub"`, "other fields are kept")
+}
+
+func TestRecorder_Options(t *testing.T) {
+	calls := 0
+	ts := newTestServer(t, &calls)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := New(path, ModeRecord,
+		WithScrubHeaders("X-Custom"),
+		WithFilter(func(i *Interaction) {
+			i.Response.Header = nil
+		}),
+	)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/transaction/get/abc", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Custom", "hidden")
+	resp, err := rec.Client().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	interactions := rec.Interactions()
+	require.Len(t, interactions, 1)
+	assert.Equal(t, transports.RedactedValue, interactions[0].Request.Header.Get("X-Custom"))
+	assert.Nil(t, interactions[0].Response.Header)
+}
+
+func TestNew_ReplayMissingFile(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "missing.json"))
+}
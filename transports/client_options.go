@@ -2,6 +2,7 @@
 package transports
 
 import (
+	"log/slog"
 	"net/http"
 	"regexp"
 )
@@ -29,29 +30,41 @@ func WithHTTPClient(serverURL string, httpClient *http.Client) ClientOps {
 	}
 }
 
-func initHTTPTransport(c *Client, serverURL string, httpClient *http.Client) {
-	useSSL := true
-	if regexHTTP.MatchString(serverURL) || regexWS.MatchString(serverURL) {
-		useSSL = false // turn off SSL if server url contains http:// or ws://
+// WithServers will use several servers, spreading requests across the healthy ones according
+// to the policy and failing over between them. Server URLs are given as for WithHTTP.
+func WithServers(serverURLs []string, policy ServerPolicy) ClientOps {
+	return func(c *Client) {
+		if c != nil && len(serverURLs) > 0 {
+			transport := initHTTPTransport(c, serverURLs[0], &http.Client{})
+			if len(serverURLs) > 1 {
+				transport.pool = newServerPool(serverURLs, policy)
+				transport.pool.probe = transport.probeServer
+			}
+		}
 	}
+}
 
-	// remove prefix if applicable
-	serverURL = regexReplaceHTTPS.ReplaceAllString(serverURL, "")
-	serverURL = regexReplaceWSS.ReplaceAllString(serverURL, "")
-
-	maxConcurrent := c.maxConcurrentRequests
-	if maxConcurrent == 0 {
-		maxConcurrent = DefaultMaxConcurrentRequests
-	}
+func initHTTPTransport(c *Client, serverURL string, httpClient *http.Client) *TransportHTTP {
+	// turn off SSL if server url contains http:// or ws://, and remove the prefix
+	serverURL, useSSL := parseServerURL(serverURL)
 
-	c.transport = NewTransportService(&TransportHTTP{
+	transport := &TransportHTTP{
 		debug:      c.debug,
 		server:     serverURL,
 		httpClient: httpClient,
 		useSSL:     useSSL,
 		version:    "v1",
-		limiter:    make(chan struct{}, maxConcurrent),
-	})
+		metrics:    c.metrics,
+	}
+	transport.SetLogger(c.logger)
+	transport.SetMaxConcurrentRequests(c.maxConcurrentRequests)
+	transport.SetAdaptiveConcurrency(c.adaptiveConcurrency)
+	transport.SetCoalescing(!c.noCoalescing)
+	transport.SetCircuitBreaker(c.circuitBreaker)
+	transport.SetRateLimiter(c.rateLimiter)
+
+	c.transport = NewTransportService(transport)
+	return transport
 }
 
 // WithToken will set the token to use in all requests
@@ -105,6 +118,84 @@ func WithMaxConcurrentRequests(n int) ClientOps {
 	return func(c *Client) {
 		if c != nil {
 			c.maxConcurrentRequests = n
+			if c.transport != nil {
+				c.transport.SetMaxConcurrentRequests(n)
+			}
+		}
+	}
+}
+
+// WithAdaptiveConcurrency makes the concurrency limit adapt to the latency and errors of
+// requests, starting from the WithMaxConcurrentRequests limit (nil keeps it fixed)
+func WithAdaptiveConcurrency(config *AdaptiveConcurrency) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.adaptiveConcurrency = config
+			if t, ok := As[ConcurrencyService](c.transport); ok {
+				t.SetAdaptiveConcurrency(config)
+			}
+		}
+	}
+}
+
+// WithMetrics sets the metrics recorder used for all requests
+func WithMetrics(metrics Metrics) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.metrics = metrics
+			if t, ok := As[MetricsService](c.transport); ok {
+				t.SetMetrics(metrics)
+			}
+		}
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker used for all requests (nil disables it)
+func WithCircuitBreaker(config *CircuitBreakerConfig) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.circuitBreaker = config
+			if t, ok := As[CircuitBreakerService](c.transport); ok {
+				t.SetCircuitBreaker(config)
+			}
+		}
+	}
+}
+
+// WithRateLimiter sets the request rate limiter used for all requests, which may be shared
+// with other transports (nil disables rate limiting)
+func WithRateLimiter(limiter *RateLimiter) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.rateLimiter = limiter
+			if t, ok := As[RateLimitService](c.transport); ok {
+				t.SetRateLimiter(limiter)
+			}
+		}
+	}
+}
+
+// WithRequestCoalescing sets whether identical GET requests in flight at the same time share
+// one round trip and response (enabled by default)
+func WithRequestCoalescing(enabled bool) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.noCoalescing = !enabled
+			if t, ok := As[CoalescingService](c.transport); ok {
+				t.SetCoalescing(enabled)
+			}
+		}
+	}
+}
+
+// WithLogger sets the structured logger used for all requests
+func WithLogger(logger *slog.Logger) ClientOps {
+	return func(c *Client) {
+		if c != nil {
+			c.logger = logger
+			if t, ok := As[LoggerService](c.transport); ok {
+				t.SetLogger(logger)
+			}
 		}
 	}
 }
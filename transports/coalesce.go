@@ -0,0 +1,115 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// CoalescingStats counts GET requests and how many of them shared the response of an
+// identical request already in flight
+type CoalescingStats struct {
+	Requests uint64 // GET requests made through the coalescer
+	Shared   uint64 // Requests answered by an identical request in flight
+}
+
+// CoalescingMetrics is optionally implemented by a Metrics recorder to count requests that
+// shared an identical request in flight
+type CoalescingMetrics interface {
+	ObserveCoalesced(route string)
+}
+
+// response is a buffered HTTP response, which coalesced requests share
+type response struct {
+	status     int
+	statusText string
+	body       []byte
+}
+
+// coalescedCall is a request in flight and the callers waiting for it
+type coalescedCall struct {
+	done    chan struct{}
+	resp    *response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalescer lets identical concurrent requests share one round trip. The round trip runs
+// until it completes or every caller waiting for it has given up.
+type coalescer struct {
+	mu       sync.Mutex
+	calls    map[string]*coalescedCall
+	requests atomic.Uint64
+	shared   atomic.Uint64
+}
+
+// newCoalescer creates an empty coalescer
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// do calls fn for key, or waits for the call already in flight for key. It reports whether
+// the response was shared, in which case the body is a copy.
+func (c *coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) (*response, error)) (*response, bool, error) {
+	c.requests.Add(1)
+	c.mu.Lock()
+	call, shared := c.calls[key]
+	if shared {
+		c.shared.Add(1)
+	} else {
+		// The round trip keeps the first caller's values but not its cancellation, which
+		// would fail everyone else waiting
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+		go c.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			c.forget(key, call)
+		}
+		c.mu.Unlock()
+		return nil, shared, ctx.Err()
+	}
+	if call.err != nil || !shared {
+		return call.resp, shared, call.err
+	}
+	resp := *call.resp
+	resp.body = bytes.Clone(resp.body)
+	return &resp, true, nil
+}
+
+// run makes the round trip for a call and removes it once done
+func (c *coalescer) run(ctx context.Context, key string, call *coalescedCall, fn func(ctx context.Context) (*response, error)) {
+	call.resp, call.err = fn(ctx)
+	c.mu.Lock()
+	c.forget(key, call)
+	c.mu.Unlock()
+	call.cancel()
+	close(call.done)
+}
+
+// forget removes a call so later requests make a new one, with the lock held
+func (c *coalescer) forget(key string, call *coalescedCall) {
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
+
+// stats returns the request counts
+func (c *coalescer) stats() CoalescingStats {
+	if c == nil {
+		return CoalescingStats{}
+	}
+	return CoalescingStats{Requests: c.requests.Load(), Shared: c.shared.Load()}
+}
@@ -0,0 +1,166 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportHTTP_Coalescing(t *testing.T) {
+	ctx := context.Background()
+	var hits int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		if r.URL.Path == "/v1/transaction/get/abc/bin" {
+			_, _ = w.Write([]byte{1, 2, 3})
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer ts.Close()
+
+	metrics := &testMetrics{}
+	service, err := NewTransport(WithHTTP(ts.URL), WithMetrics(metrics), WithMaxConcurrentRequests(1))
+	require.NoError(t, err)
+	transport := service.(*TransportHTTP)
+
+	t.Run("identical requests share a round trip", func(t *testing.T) {
+		var wg sync.WaitGroup
+		raws := make([][]byte, 5)
+		for i := range raws {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				raw, err := transport.GetRawTransaction(ctx, "abc")
+				assert.NoError(t, err)
+				raws[i] = raw
+			}(i)
+		}
+		require.Eventually(t, func() bool { return transport.CoalescingStats().Requests == 5 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.SwapInt32(&hits, 0))
+		assert.Equal(t, CoalescingStats{Requests: 5, Shared: 4}, transport.CoalescingStats())
+		assert.Equal(t, 4, metrics.coalesced)
+		raws[0][0] = 9
+		for _, raw := range raws[1:] {
+			assert.Equal(t, []byte{1, 2, 3}, raw, "each caller gets its own copy")
+		}
+	})
+
+	t.Run("a cancelled caller does not fail the others", func(t *testing.T) {
+		transport.SetCoalescing(false)
+		transport.SetCoalescing(true)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := transport.GetTransaction(cancelled, "abc")
+		require.ErrorIs(t, err, context.Canceled)
+		tx, err := transport.GetTransaction(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", tx.ID)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		transport.SetCoalescing(false)
+		_, err := transport.GetRawTransaction(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, CoalescingStats{}, transport.CoalescingStats())
+	})
+
+	t.Run("disabled decodes from the stream", func(t *testing.T) {
+		unblock := make(chan struct{})
+		stream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id":"abc"}`))
+			w.(http.Flusher).Flush()
+			select {
+			case <-unblock:
+			case <-r.Context().Done():
+			}
+		}))
+		defer stream.Close()
+		defer close(unblock)
+
+		service, err := NewTransport(WithHTTP(stream.URL), WithRequestCoalescing(false))
+		require.NoError(t, err)
+		timeout, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		tx, err := service.GetTransaction(timeout, "abc")
+		require.NoError(t, err, "the body is not read to the end")
+		assert.Equal(t, "abc", tx.ID)
+	})
+}
+
+func TestCoalescer_AllCallersGiveUp(t *testing.T) {
+	c := newCoalescer()
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, _, err := c.do(ctx, "key", func(ctx context.Context) (*response, error) {
+			close(started)
+			<-ctx.Done()
+			stopped <- ctx.Err()
+			return nil, ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	}()
+	<-started
+	cancel()
+	require.ErrorIs(t, <-stopped, context.Canceled, "the round trip is cancelled")
+
+	resp, shared, err := c.do(context.Background(), "key", func(context.Context) (*response, error) {
+		return &response{status: http.StatusOK}, nil
+	})
+	require.NoError(t, err)
+	assert.False(t, shared, "a later request makes a new round trip")
+	assert.Equal(t, http.StatusOK, resp.status)
+}
+
+func TestTransportHTTP_CoalescingRequestBody(t *testing.T) {
+	// Both logins must reach the server, which answers only once it has seen both
+	var hits int32
+	both := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var login map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&login)
+		if atomic.AddInt32(&hits, 1) == 2 {
+			close(both)
+		}
+		select {
+		case <-both:
+		case <-time.After(time.Second):
+		}
+		if login[FieldPassword] != "secret" {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"token-` + login[FieldUsername] + `"}`))
+	}))
+	defer ts.Close()
+
+	service, err := NewTransport(WithHTTP(ts.URL))
+	require.NoError(t, err)
+	transport := service.(*TransportHTTP)
+
+	ctx := context.Background()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- transport.Login(ctx, "mallory", "guess")
+	}()
+	require.NoError(t, transport.Login(ctx, "alice", "secret"))
+	require.ErrorIs(t, <-errs, ErrFailedLogin, "the other login does not get alice's token")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.Equal(t, "token-alice", transport.GetToken())
+	assert.Zero(t, transport.CoalescingStats().Shared)
+}
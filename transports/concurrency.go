@@ -0,0 +1,248 @@
+package transports
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxAdaptiveConcurrency is the highest limit an adaptive concurrency limiter grows to
+	DefaultMaxAdaptiveConcurrency = 64
+	// DefaultLatencyTolerance is how many times its baseline latency a route may take before
+	// the adaptive limiter treats it as a sign of server stress
+	DefaultLatencyTolerance = 2.0
+	// DefaultConcurrencyBackoff is the factor an adaptive limiter shrinks by under stress
+	DefaultConcurrencyBackoff = 0.75
+)
+
+// minShrinkInterval is the least time between two decreases of an adaptive limit
+const minShrinkInterval = 100 * time.Millisecond
+
+// baselineDrift is the weight of a slower sample in a route's baseline latency, so the
+// baseline follows the server when it gets slower for good
+const baselineDrift = 0.01
+
+// AdaptiveConcurrency configures an AIMD concurrency limit: it grows by one for every limit's
+// worth of successful requests while requests are queueing (e.g. during a backfill), and
+// shrinks by Backoff when a request fails with a network error, 5xx or 429, or takes longer
+// than LatencyTolerance times the baseline latency of its route.
+type AdaptiveConcurrency struct {
+	// Min is the lowest limit (1 if 0)
+	Min int
+	// Max is the highest limit (DefaultMaxAdaptiveConcurrency if 0)
+	Max int
+	// LatencyTolerance is the multiple of a route's baseline latency that counts as slow
+	// (DefaultLatencyTolerance if 0)
+	LatencyTolerance float64
+	// Backoff is the factor the limit is multiplied by under stress (DefaultConcurrencyBackoff if 0)
+	Backoff float64
+}
+
+// ConcurrencyStats is a snapshot of the concurrency limiter
+type ConcurrencyStats struct {
+	Limit    int  // Current maximum number of concurrent requests
+	InFlight int  // Requests holding a slot
+	Waiting  int  // Requests waiting for a slot
+	Adaptive bool // Whether the limit adapts to latency and errors
+}
+
+// ConcurrencyMetrics is optionally implemented by a Metrics recorder to receive the
+// concurrency limit whenever it changes
+type ConcurrencyMetrics interface {
+	SetConcurrencyLimit(limit int)
+}
+
+// concurrencyLimiter caps the number of requests in flight. The limit can be changed at any
+// time; lowering it lets in-flight requests finish and admits new ones as they do.
+type concurrencyLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	inFlight  int
+	waiters   []chan struct{}
+	adaptive  *AdaptiveConcurrency
+	successes int                      // successful requests since the limit last grew
+	baselines map[string]time.Duration // lowest recent latency per route
+	shrunkAt  time.Time
+	onChange  func(limit int)
+}
+
+// newConcurrencyLimiter creates a limiter with a fixed limit
+func newConcurrencyLimiter(limit int) *concurrencyLimiter {
+	if limit <= 0 {
+		limit = DefaultMaxConcurrentRequests
+	}
+	return &concurrencyLimiter{limit: limit}
+}
+
+// acquire blocks until a slot is available or the context is cancelled
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, w := range l.waiters {
+			if w == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// The slot was granted as the context was cancelled, pass it on
+		l.inFlight--
+		l.admit()
+		return ctx.Err()
+	}
+}
+
+// release returns a slot
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.admit()
+}
+
+// admit hands free slots to waiters in order, with the lock held
+func (l *concurrencyLimiter) admit() {
+	for l.inFlight < l.limit && len(l.waiters) > 0 {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// setLimit changes the limit, clamped to the adaptive range if any
+func (l *concurrencyLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	changed := l.resize(limit)
+	limit, onChange := l.limit, l.onChange
+	l.mu.Unlock()
+	if changed && onChange != nil {
+		onChange(limit)
+	}
+}
+
+// resize sets the limit with the lock held, returning whether it changed
+func (l *concurrencyLimiter) resize(limit int) bool {
+	if l.adaptive != nil {
+		limit = min(max(limit, l.adaptive.Min), l.adaptive.Max)
+	}
+	limit = max(limit, 1)
+	if limit == l.limit {
+		return false
+	}
+	l.limit = limit
+	l.successes = 0
+	l.admit()
+	return true
+}
+
+// setAdaptive turns adaptive limiting on, filling in defaults, or off with nil
+func (l *concurrencyLimiter) setAdaptive(config *AdaptiveConcurrency) {
+	if config != nil {
+		c := *config
+		if c.Min <= 0 {
+			c.Min = 1
+		}
+		if c.Max <= 0 {
+			c.Max = DefaultMaxAdaptiveConcurrency
+		}
+		c.Max = max(c.Max, c.Min)
+		if c.LatencyTolerance <= 1 {
+			c.LatencyTolerance = DefaultLatencyTolerance
+		}
+		if c.Backoff <= 0 || c.Backoff >= 1 {
+			c.Backoff = DefaultConcurrencyBackoff
+		}
+		config = &c
+	}
+
+	l.mu.Lock()
+	l.adaptive = config
+	l.baselines = make(map[string]time.Duration)
+	changed := l.resize(l.limit)
+	limit, onChange := l.limit, l.onChange
+	l.mu.Unlock()
+	if changed && onChange != nil {
+		onChange(limit)
+	}
+}
+
+// record adapts the limit to the outcome of a request, if adaptive
+func (l *concurrencyLimiter) record(ctx context.Context, route string, resp *http.Response, err error, latency time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if l.adaptive == nil {
+		l.mu.Unlock()
+		return
+	}
+
+	stressed := false
+	switch requestOutcome(ctx, resp, err) {
+	case outcomeIgnored:
+		l.mu.Unlock()
+		return
+	case outcomeFailure:
+		stressed = true
+	default:
+		baseline, ok := l.baselines[route]
+		switch {
+		case !ok || latency < baseline:
+			l.baselines[route] = latency
+		case float64(latency) > l.adaptive.LatencyTolerance*float64(baseline):
+			stressed = true
+		default:
+			l.baselines[route] = baseline + time.Duration(baselineDrift*float64(latency-baseline))
+		}
+	}
+
+	var changed bool
+	now := time.Now()
+	if stressed {
+		// Shrink at most once per baseline round trip, as requests started before the last
+		// decrease report the same stress
+		if now.Sub(l.shrunkAt) > max(l.baselines[route], minShrinkInterval) {
+			l.shrunkAt = now
+			changed = l.resize(int(math.Floor(float64(l.limit) * l.adaptive.Backoff)))
+		}
+	} else if len(l.waiters) > 0 || l.inFlight >= l.limit {
+		// Only grow while the limit is what holds requests back
+		l.successes++
+		if l.successes >= l.limit {
+			changed = l.resize(l.limit + 1)
+		}
+	}
+	limit, onChange := l.limit, l.onChange
+	l.mu.Unlock()
+	if changed && onChange != nil {
+		onChange(limit)
+	}
+}
+
+// stats returns a snapshot of the limiter
+func (l *concurrencyLimiter) stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencyStats{
+		Limit:    l.limit,
+		InFlight: l.inFlight,
+		Waiting:  len(l.waiters),
+		Adaptive: l.adaptive != nil,
+	}
+}
@@ -0,0 +1,111 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resize while in flight", func(t *testing.T) {
+		l := newConcurrencyLimiter(2)
+		require.NoError(t, l.acquire(ctx))
+		require.NoError(t, l.acquire(ctx))
+
+		acquired := make(chan struct{})
+		go func() {
+			_ = l.acquire(ctx)
+			close(acquired)
+		}()
+		require.Eventually(t, func() bool { return l.stats().Waiting == 1 }, time.Second, time.Millisecond)
+
+		l.setLimit(3)
+		<-acquired
+		assert.Equal(t, ConcurrencyStats{Limit: 3, InFlight: 3}, l.stats())
+
+		// Lowering the limit lets in-flight requests finish
+		l.setLimit(1)
+		l.release()
+		l.release()
+		short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, l.acquire(short), context.DeadlineExceeded)
+		assert.Equal(t, ConcurrencyStats{Limit: 1, InFlight: 1}, l.stats())
+		l.release()
+		require.NoError(t, l.acquire(ctx))
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		l := newConcurrencyLimiter(4)
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%10 == 0 {
+					l.setLimit(1 + i%7)
+				}
+				require.NoError(t, l.acquire(ctx))
+				assert.LessOrEqual(t, l.stats().InFlight, 7)
+				l.release()
+			}(i)
+		}
+		wg.Wait()
+		assert.Zero(t, l.stats().InFlight)
+		assert.Zero(t, l.stats().Waiting)
+	})
+
+	t.Run("adaptive", func(t *testing.T) {
+		l := newConcurrencyLimiter(2)
+		var changes []int
+		l.onChange = func(limit int) { changes = append(changes, limit) }
+		l.setAdaptive(&AdaptiveConcurrency{Min: 2, Max: 3})
+		ok := &http.Response{StatusCode: http.StatusOK}
+		route := "/transaction/get/{txid}"
+
+		// Successes grow the limit only while it is saturated
+		require.NoError(t, l.acquire(ctx))
+		l.record(ctx, route, ok, nil, 10*time.Millisecond)
+		l.record(ctx, route, ok, nil, 10*time.Millisecond)
+		assert.Equal(t, 2, l.stats().Limit)
+		require.NoError(t, l.acquire(ctx))
+		for i := 0; i < 5; i++ {
+			l.record(ctx, route, ok, nil, 10*time.Millisecond)
+		}
+		assert.Equal(t, 3, l.stats().Limit, "never above the maximum")
+
+		// A slow response shrinks it, a cancelled request does not count
+		l.record(ctx, route, ok, nil, time.Second)
+		assert.Equal(t, 2, l.stats().Limit, "never below the minimum")
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		l.record(cancelled, route, nil, context.Canceled, 0)
+		assert.Equal(t, []int{3, 2}, changes)
+
+		l.setAdaptive(nil)
+		l.record(ctx, route, &http.Response{StatusCode: http.StatusBadGateway}, nil, 0)
+		assert.Equal(t, ConcurrencyStats{Limit: 2, InFlight: 2}, l.stats())
+	})
+}
+
+func TestTransportHTTP_SetMaxConcurrentRequests(t *testing.T) {
+	service, err := NewTransport(WithHTTP("http://localhost"), WithMaxConcurrentRequests(3),
+		WithAdaptiveConcurrency(&AdaptiveConcurrency{Min: 2, Max: 10}))
+	require.NoError(t, err)
+	concurrency, ok := As[ConcurrencyService](service)
+	require.True(t, ok)
+	assert.Equal(t, ConcurrencyStats{Limit: 3, Adaptive: true}, concurrency.ConcurrencyStats())
+
+	service.SetMaxConcurrentRequests(20)
+	assert.Equal(t, 10, concurrency.ConcurrencyStats().Limit, "clamped to the adaptive range")
+	concurrency.SetAdaptiveConcurrency(nil)
+	service.SetMaxConcurrentRequests(0)
+	assert.Equal(t, ConcurrencyStats{Limit: DefaultMaxConcurrentRequests}, concurrency.ConcurrencyStats())
+}
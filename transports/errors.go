@@ -6,3 +6,11 @@ import "errors"
 var ErrNoClientSet = errors.New("no transport client set")
 var ErrFailedLogin = errors.New("failed to login to server")
 var ErrNotFound = errors.New("not found")
+
+// ErrCircuitOpen is returned without making a request while the circuit breaker for the
+// request's route is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// ErrNotSupported is returned when the transport service does not support a feature, e.g. a
+// custom transport service without StreamService
+var ErrNotSupported = errors.New("not supported by transport")
@@ -7,9 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/b-open-io/go-junglebus/models"
 )
@@ -24,7 +25,13 @@ type TransportHTTP struct {
 	token      string
 	useSSL     bool
 	version    string
-	limiter    chan struct{}
+	limiter    *concurrencyLimiter
+	metrics    Metrics
+	logger     *slog.Logger
+	pool       *serverPool // set when using several servers
+	breaker    *circuitBreaker
+	rate       *RateLimiter
+	coalescer  *coalescer // nil when coalescing is disabled
 }
 
 // SetDebug turn the debugging on or off
@@ -37,14 +44,22 @@ func (h *TransportHTTP) IsDebug() bool {
 	return h.debug
 }
 
-// UseSSL turn the SSL on or off
+// UseSSL turn the SSL on or off (for every server when using several)
 func (h *TransportHTTP) UseSSL(useSSL bool) {
 	h.useSSL = useSSL
+	if h.pool != nil {
+		h.pool.mu.Lock()
+		for _, s := range h.pool.servers {
+			s.status.SSL = useSSL
+		}
+		h.pool.mu.Unlock()
+	}
 }
 
-// IsSSL return the SSL status
+// IsSSL return the SSL status (of the server GetServerURL returns when using several)
 func (h *TransportHTTP) IsSSL() bool {
-	return h.useSSL
+	_, useSSL := h.SelectServer()
+	return useSSL
 }
 
 // SetToken sets the token to use for all requests manually
@@ -99,17 +114,147 @@ func (h *TransportHTTP) GetVersion() string {
 	return h.version
 }
 
-// GetServerURL get the server URL for this transport
+// GetServerURL get the server URL for this transport, or with several servers the one to
+// use next
 func (h *TransportHTTP) GetServerURL() string {
-	return h.server
+	server, _ := h.SelectServer()
+	return server
+}
+
+// SelectServer returns the server to use for a new connection and whether to use SSL
+func (h *TransportHTTP) SelectServer() (server string, useSSL bool) {
+	if h.pool == nil {
+		return h.server, h.useSSL
+	}
+	h.pool.probeDue()
+	return h.pool.address(h.pool.pick(nil))
+}
+
+// MarkServerDown takes a server out of rotation until a health probe succeeds, e.g. after a
+// subscription fails to connect to it. It does nothing with a single server.
+func (h *TransportHTTP) MarkServerDown(server string, err error) {
+	if h.pool != nil {
+		h.pool.markDown(server, err)
+		h.log().Warn("server down", slog.String("server", server), slog.Any("error", err))
+	}
+}
+
+// ServerStatus returns the health of each server, or nil with a single server
+func (h *TransportHTTP) ServerStatus() []ServerStatus {
+	if h.pool == nil {
+		return nil
+	}
+	return h.pool.statuses()
+}
+
+// ProbeServers health checks every server now and returns their health, or nil with a
+// single server
+func (h *TransportHTTP) ProbeServers(ctx context.Context) []ServerStatus {
+	if h.pool == nil {
+		return nil
+	}
+	return h.pool.probeAll(ctx)
 }
 
-// SetMaxConcurrentRequests sets the maximum number of concurrent HTTP requests.
+// SetMaxConcurrentRequests sets the maximum number of concurrent HTTP requests. It is safe to
+// call while requests are in flight; with adaptive concurrency it sets the current limit,
+// within the adaptive range.
 func (h *TransportHTTP) SetMaxConcurrentRequests(n int) {
 	if n <= 0 {
 		n = DefaultMaxConcurrentRequests
 	}
-	h.limiter = make(chan struct{}, n)
+	if h.limiter == nil {
+		h.limiter = newConcurrencyLimiter(n)
+		h.limiter.onChange = h.observeConcurrencyLimit
+		if m, ok := h.metrics.(ConcurrencyMetrics); ok {
+			m.SetConcurrencyLimit(n)
+		}
+		return
+	}
+	h.limiter.setLimit(n)
+}
+
+// SetAdaptiveConcurrency makes the concurrency limit adapt to the latency and errors of
+// requests (nil keeps the current limit fixed)
+func (h *TransportHTTP) SetAdaptiveConcurrency(config *AdaptiveConcurrency) {
+	if h.limiter == nil {
+		h.SetMaxConcurrentRequests(DefaultMaxConcurrentRequests)
+	}
+	h.limiter.setAdaptive(config)
+}
+
+// ConcurrencyStats returns the current concurrency limit and usage
+func (h *TransportHTTP) ConcurrencyStats() ConcurrencyStats {
+	if h.limiter == nil {
+		return ConcurrencyStats{}
+	}
+	return h.limiter.stats()
+}
+
+// observeConcurrencyLimit reports a new concurrency limit to the metrics recorder, if it
+// supports it
+func (h *TransportHTTP) observeConcurrencyLimit(limit int) {
+	h.log().Debug("concurrency limit changed", slog.Int("limit", limit))
+	if m, ok := h.metrics.(ConcurrencyMetrics); ok {
+		m.SetConcurrencyLimit(limit)
+	}
+}
+
+// SetLogger sets the structured logger for all requests (nil silences logging unless debugging is on)
+func (h *TransportHTTP) SetLogger(logger *slog.Logger) {
+	h.logger = nil
+	if logger != nil {
+		h.logger = ResolveLogger(logger, false)
+	}
+}
+
+// log returns the logger to use, with secrets redacted
+func (h *TransportHTTP) log() *slog.Logger {
+	return ResolveLogger(h.logger, h.debug)
+}
+
+// SetMetrics sets the metrics recorder for all requests (nil disables metrics)
+func (h *TransportHTTP) SetMetrics(metrics Metrics) {
+	h.metrics = metrics
+	if m, ok := metrics.(ConcurrencyMetrics); ok && h.limiter != nil {
+		m.SetConcurrencyLimit(h.limiter.stats().Limit)
+	}
+}
+
+// SetCircuitBreaker sets the circuit breaker configuration for all requests (nil disables it)
+func (h *TransportHTTP) SetCircuitBreaker(config *CircuitBreakerConfig) {
+	h.breaker = nil
+	if config != nil {
+		h.breaker = newCircuitBreaker(*config, h.log)
+	}
+}
+
+// SetRateLimiter sets the request rate limiter for all requests, which may be shared with
+// other transports (nil disables rate limiting)
+func (h *TransportHTTP) SetRateLimiter(limiter *RateLimiter) {
+	h.rate = limiter
+}
+
+// SetCoalescing sets whether identical GET requests in flight at the same time share one
+// round trip and response
+func (h *TransportHTTP) SetCoalescing(enabled bool) {
+	switch {
+	case !enabled:
+		h.coalescer = nil
+	case h.coalescer == nil:
+		h.coalescer = newCoalescer()
+	}
+}
+
+// CoalescingStats returns how many GET requests shared the response of an identical request
+func (h *TransportHTTP) CoalescingStats() CoalescingStats {
+	return h.coalescer.stats()
+}
+
+// CircuitStates returns the circuit breaker state of each route class that has been
+// requested, or nil without a circuit breaker
+func (h *TransportHTTP) CircuitStates() map[string]CircuitState {
+	return h.breaker.states()
 }
 
 func (h *TransportHTTP) Login(ctx context.Context, username string, password string) error {
@@ -128,12 +273,9 @@ func (h *TransportHTTP) Login(ctx context.Context, username string, password str
 	); err != nil {
 		return err
 	}
-	if h.debug {
-		log.Printf("Login: %v\n", loginResponse)
-	}
-
-	if token, ok := loginResponse["token"]; ok {
-		h.SetToken(token.(string))
+	if token, ok := loginResponse["token"].(string); ok {
+		h.log().Debug("logged in", slog.String("username", username))
+		h.SetToken(token)
 		return nil
 	}
 
@@ -148,8 +290,9 @@ func (h *TransportHTTP) GetTransaction(ctx context.Context, txID string) (transa
 	); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("Transaction: %v\n", transaction)
+	if transaction != nil {
+		h.log().Debug("transaction", slog.String("txid", txID), slog.Int("bytes", len(transaction.Transaction)),
+			slog.Uint64("block", uint64(transaction.BlockHeight)))
 	}
 
 	return transaction, nil
@@ -176,9 +319,8 @@ func (h *TransportHTTP) GetAddressTransactions(ctx context.Context, address stri
 	if err = h.doHTTPRequest(ctx, http.MethodGet, url, nil, &addr); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("Address transactions: %v\n", addr)
-	}
+	h.log().Debug("address transactions", slog.String("address", address),
+		slog.Uint64("from_height", uint64(fromHeight)), slog.Int("count", len(addr)))
 	return addr, nil
 }
 
@@ -188,9 +330,8 @@ func (h *TransportHTTP) GetAddressTransactionDetails(ctx context.Context, addres
 	if err = h.doHTTPRequest(ctx, http.MethodGet, url, nil, &transactions); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("transactions: %d\n", len(transactions))
-	}
+	h.log().Debug("address transaction details", slog.String("address", address),
+		slog.Uint64("from_height", uint64(fromHeight)), slog.Int("count", len(transactions)))
 	return transactions, nil
 }
 
@@ -203,9 +344,7 @@ func (h *TransportHTTP) GetBlockHeader(ctx context.Context, block string) (block
 	); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("transactions: %v\n", blockHeader)
-	}
+	h.log().Debug("block header", slog.String("block", block), slog.Any("header", blockHeader))
 
 	return blockHeader, nil
 }
@@ -219,9 +358,7 @@ func (h *TransportHTTP) GetBlockHeaders(ctx context.Context, fromBlock string, l
 	); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("transactions: %v\n", blockHeaders)
-	}
+	h.log().Debug("block headers", slog.String("from_block", fromBlock), slog.Int("count", len(blockHeaders)))
 
 	return blockHeaders, nil
 }
@@ -232,9 +369,8 @@ func (h *TransportHTTP) GetFromBlock(ctx context.Context, subscriptionID string,
 	if err := h.doHTTPRequest(ctx, http.MethodGet, url, nil, &transactions); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("GetFromBlock transactions: %d\n", len(transactions))
-	}
+	h.log().Debug("transactions from block", slog.String("subscription_id", subscriptionID),
+		slog.Uint64("block", uint64(height)), slog.Uint64("last_idx", lastIdx), slog.Int("count", len(transactions)))
 	return transactions, nil
 }
 
@@ -244,9 +380,8 @@ func (h *TransportHTTP) GetLiteFromBlock(ctx context.Context, subscriptionID str
 	if err := h.doHTTPRequest(ctx, http.MethodGet, url, nil, &transactions); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("GetLiteFromBlock transactions: %d\n", len(transactions))
-	}
+	h.log().Debug("lite transactions from block", slog.String("subscription_id", subscriptionID),
+		slog.Uint64("block", uint64(height)), slog.Uint64("last_idx", lastIdx), slog.Int("count", len(transactions)))
 	return transactions, nil
 }
 
@@ -266,9 +401,7 @@ func (h *TransportHTTP) GetUser(ctx context.Context) (*models.User, error) {
 	if err := h.doHTTPRequest(ctx, http.MethodGet, url, nil, &user); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("GetUser: %v\n", user)
-	}
+	h.log().Debug("user", slog.Any("user", user))
 	return user, nil
 }
 
@@ -279,97 +412,157 @@ func (h *TransportHTTP) GetChainTip(ctx context.Context) (blockHeader *models.Bl
 	); err != nil {
 		return nil, err
 	}
-	if h.debug {
-		log.Printf("chain tip: %v\n", blockHeader)
-	}
+	h.log().Debug("chain tip", slog.Any("header", blockHeader))
 	return blockHeader, nil
 }
 
-// acquire blocks until a limiter slot is available or context is cancelled.
+// acquire blocks until the rate limiter allows a request and a limiter slot is available,
+// or context is cancelled.
 func (h *TransportHTTP) acquire(ctx context.Context) error {
-	if h.limiter == nil {
+	if h.limiter == nil && h.rate == nil {
 		return nil
 	}
-	select {
-	case h.limiter <- struct{}{}:
+	if h.metrics != nil {
+		start := time.Now()
+		defer func() {
+			h.metrics.ObserveLimiterWait(time.Since(start))
+		}()
+	}
+	if err := h.rate.Wait(ctx); err != nil {
+		return err
+	}
+	if h.limiter == nil {
 		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+	}
+	return h.limiter.acquire(ctx)
+}
+
+// observeRequest logs a finished request and reports it to the metrics recorder, if any
+func (h *TransportHTTP) observeRequest(method, path string, resp *http.Response, start time.Time) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	route := RouteTemplate(path)
+	latency := time.Since(start)
+
+	level := slog.LevelDebug
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	h.log().LogAttrs(context.Background(), level, "request",
+		slog.String("method", method),
+		slog.String("route", route),
+		slog.Int("status", status),
+		slog.Duration("latency", latency),
+	)
+
+	if h.metrics != nil {
+		h.metrics.ObserveRequest(method, route, status, latency)
 	}
 }
 
 // release returns a limiter slot.
 func (h *TransportHTTP) release() {
 	if h.limiter != nil {
-		<-h.limiter
+		h.limiter.release()
 	}
 }
 
 // doHTTPRequest will create and submit the HTTP request
 func (h *TransportHTTP) doHTTPRequest(ctx context.Context, method string, path string, rawJSON []byte, responseJSON interface{}) error {
-	if err := h.acquire(ctx); err != nil {
-		return err
-	}
-	defer h.release()
+	return h.fetch(ctx, method, path, rawJSON, "application/json", func(status int, statusText string, body io.Reader) error {
+		if status == http.StatusNotFound {
+			// The status stays in the message for callers matching on it
+			return fmt.Errorf("server error: %d - %s: %w", status, statusText, ErrNotFound)
+		}
+		if status >= http.StatusBadRequest {
+			return errors.New("server error: " + strconv.Itoa(status) + " - " + statusText)
+		}
+		return json.NewDecoder(body).Decode(responseJSON)
+	})
+}
 
-	protocol := "https"
-	if !h.useSSL {
-		protocol = "http"
-	}
-	serverRequest := fmt.Sprintf("%s://%s/%s%s", protocol, h.server, h.version, path)
-	req, err := http.NewRequestWithContext(ctx, method, serverRequest, bytes.NewBuffer(rawJSON))
-	if err != nil {
+// doHTTPRequestBinary will create and submit an HTTP request returning binary data
+func (h *TransportHTTP) doHTTPRequestBinary(ctx context.Context, method string, path string) (data []byte, err error) {
+	err = h.fetch(ctx, method, path, nil, "", func(status int, statusText string, body io.Reader) error {
+		if status == http.StatusNotFound {
+			return ErrNotFound
+		}
+		if status >= http.StatusBadRequest {
+			return errors.New("server error: " + strconv.Itoa(status) + " - " + statusText)
+		}
+		data, err = io.ReadAll(body)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("token", h.token)
-
-	var resp *http.Response
-	defer func() {
-		if resp != nil && resp.Body != nil {
-			_ = resp.Body.Close()
+	return data, nil
+}
+
+// fetch submits a request and passes the response to read. Identical GET requests without a
+// body in flight at the same time share one round trip, unless coalescing is disabled; only
+// then is the response buffered, so it can be read by each caller. Requests with a body, such
+// as Login, are never shared since the key does not include it.
+func (h *TransportHTTP) fetch(ctx context.Context, method string, path string, body []byte, contentType string,
+	read func(status int, statusText string, body io.Reader) error,
+) error {
+	if method != http.MethodGet || body != nil || h.coalescer == nil {
+		return h.roundTrip(ctx, method, path, body, contentType, func(resp *http.Response) error {
+			return read(resp.StatusCode, resp.Status, resp.Body)
+		})
+	}
+	resp, shared, err := h.coalescer.do(ctx, method+" "+path, func(ctx context.Context) (buffered *response, err error) {
+		err = h.roundTrip(ctx, method, path, body, contentType, func(resp *http.Response) error {
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			buffered = &response{status: resp.StatusCode, statusText: resp.Status, body: data}
+			return nil
+		})
+		return buffered, err
+	})
+	if shared {
+		h.log().Debug("coalesced request", slog.String("route", RouteTemplate(path)))
+		if m, ok := h.metrics.(CoalescingMetrics); ok {
+			m.ObserveCoalesced(RouteTemplate(path))
 		}
-	}()
-	if resp, err = h.httpClient.Do(req); err != nil {
-		return err
 	}
-
-	if resp.StatusCode >= http.StatusBadRequest {
-		return errors.New("server error: " + strconv.Itoa(resp.StatusCode) + " - " + resp.Status)
+	if err != nil {
+		return err
 	}
-
-	return json.NewDecoder(resp.Body).Decode(responseJSON)
+	return read(resp.status, resp.statusText, bytes.NewReader(resp.body))
 }
 
-// doHTTPRequestBinary will create and submit an HTTP request returning binary data
-func (h *TransportHTTP) doHTTPRequestBinary(ctx context.Context, method string, path string) ([]byte, error) {
-
-	protocol := "https"
-	if !h.useSSL {
-		protocol = "http"
-	}
-	serverRequest := fmt.Sprintf("%s://%s/%s%s", protocol, h.server, h.version, path)
-	req, err := http.NewRequestWithContext(ctx, method, serverRequest, nil)
+// roundTrip submits a request through the circuit breaker and limiters and passes the
+// response to read before closing it
+func (h *TransportHTTP) roundTrip(ctx context.Context, method string, path string, body []byte, contentType string,
+	read func(resp *http.Response) error,
+) error {
+	done, err := h.breaker.allow(path)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err = h.acquire(ctx); err != nil {
+		done(ctx, nil, err)
+		return err
 	}
-	req.Header.Set("token", h.token)
+	defer h.release()
 
-	var resp *http.Response
+	start := time.Now()
+	resp, err := h.send(ctx, method, path, body, contentType)
+	done(ctx, resp, err)
+	h.limiter.record(ctx, RouteTemplate(path), resp, err, time.Since(start))
 	defer func() {
+		h.observeRequest(method, path, resp, start)
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
 		}
 	}()
-	if resp, err = h.httpClient.Do(req); err != nil {
-		return nil, err
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, ErrNotFound
-	}
-	if resp.StatusCode >= http.StatusBadRequest {
-		return nil, errors.New("server error: " + strconv.Itoa(resp.StatusCode) + " - " + resp.Status)
+	if err != nil {
+		return err
 	}
-
-	return io.ReadAll(resp.Body)
+	return read(resp)
 }
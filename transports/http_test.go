@@ -309,7 +309,7 @@ func TestTransportHTTP_ErrorHandling(t *testing.T) {
 	transport.useSSL = false
 
 	_, err := transport.GetTransaction(context.Background(), "test-tx")
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrNotFound)
 	assert.Contains(t, err.Error(), "404")
 }
 
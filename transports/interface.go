@@ -2,6 +2,7 @@ package transports
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/b-open-io/go-junglebus/models"
 )
@@ -62,7 +63,84 @@ type TransportService interface {
 	SetMaxConcurrentRequests(n int)
 }
 
+// The interfaces below are optional features of a transport service. Callers detect them
+// with As, so transport services and mocks written against TransportService keep working.
+
+// StreamService is the streaming address related requests
+type StreamService interface {
+	StreamAddressTransactions(ctx context.Context, address string, fromHeight uint32, limit uint) (*ArrayReader[models.AddressTx], error)
+	StreamAddressTransactionDetails(ctx context.Context, address string, fromHeight uint32, limit uint) (*ArrayReader[models.Transaction], error)
+}
+
+// ServerService is the server selection and health related requests
+type ServerService interface {
+	SelectServer() (server string, useSSL bool)
+	MarkServerDown(server string, err error)
+	ServerStatus() []ServerStatus
+	ProbeServers(ctx context.Context) []ServerStatus
+}
+
+// ConcurrencyService is the adaptive concurrency limit of a transport service
+type ConcurrencyService interface {
+	SetAdaptiveConcurrency(config *AdaptiveConcurrency)
+	ConcurrencyStats() ConcurrencyStats
+}
+
+// CoalescingService is the sharing of identical GET requests in flight
+type CoalescingService interface {
+	SetCoalescing(enabled bool)
+	CoalescingStats() CoalescingStats
+}
+
+// CircuitBreakerService is the circuit breaker of a transport service
+type CircuitBreakerService interface {
+	SetCircuitBreaker(config *CircuitBreakerConfig)
+	CircuitStates() map[string]CircuitState
+}
+
+// RateLimitService is the rate limiter of a transport service
+type RateLimitService interface {
+	SetRateLimiter(limiter *RateLimiter)
+}
+
+// MetricsService is the metrics recorder of a transport service
+type MetricsService interface {
+	SetMetrics(metrics Metrics)
+}
+
+// LoggerService is the logger of a transport service
+type LoggerService interface {
+	SetLogger(logger *slog.Logger)
+}
+
+// Unwrapper is implemented by transport services that wrap another one, e.g. to add tracing,
+// so the optional features of the wrapped transport service can still be found
+type Unwrapper interface {
+	Unwrap() TransportService
+}
+
+// As returns the transport service, or the first one it wraps, that implements T
+func As[T any](t TransportService) (T, bool) {
+	for t != nil {
+		if v, ok := t.(T); ok {
+			return v, true
+		}
+		u, ok := t.(Unwrapper)
+		if !ok {
+			break
+		}
+		t = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
 // LoginResponse response from server on login or token refresh
 type LoginResponse struct {
 	Token string `json:"token"`
 }
+
+// LogValue implements slog.LogValuer so the token is never logged
+func (r LoginResponse) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("token", RedactedValue))
+}
@@ -0,0 +1,107 @@
+package transports
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RedactedValue replaces secrets in log output
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are always redacted (compared case-insensitively)
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"password":      true,
+	"private_key":   true,
+	"privatekey":    true,
+	"authorization": true,
+}
+
+var (
+	// discardLogger is used when no logger is set, so the client is silent by default
+	discardLogger = slog.New(discardHandler{})
+	// debugLogger is used when debugging is turned on without a logger
+	debugLogger = slog.New(NewRedactingHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+)
+
+// ResolveLogger returns the logger to use: the given logger with secrets redacted, a debug logger
+// writing to stderr if debug is on, or a logger that discards everything
+func ResolveLogger(logger *slog.Logger, debug bool) *slog.Logger {
+	switch {
+	case logger != nil:
+		if _, ok := logger.Handler().(*redactingHandler); ok {
+			return logger
+		}
+		return slog.New(NewRedactingHandler(logger.Handler()))
+	case debug:
+		return debugLogger
+	default:
+		return discardLogger
+	}
+}
+
+// discardHandler drops all log records
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// redactingHandler replaces the values of sensitive attributes before passing records on
+type redactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps a handler so that tokens, passwords and private keys are never logged
+func NewRedactingHandler(next slog.Handler) slog.Handler {
+	return &redactingHandler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr redacts an attribute if its key is sensitive, descending into groups
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, RedactedValue)
+	}
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	}
+	return a
+}
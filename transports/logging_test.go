@@ -0,0 +1,77 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestResolveLogger(t *testing.T) {
+	// Silent by default
+	logger := ResolveLogger(nil, false)
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelError))
+
+	// Debugging without a logger logs debug records
+	logger = ResolveLogger(nil, true)
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	// A given logger is wrapped only once
+	var buf bytes.Buffer
+	logger = ResolveLogger(newJSONLogger(&buf), false)
+	assert.Same(t, logger, ResolveLogger(logger, false))
+}
+
+func TestRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := ResolveLogger(newJSONLogger(&buf), false)
+
+	logger.With(slog.String("token", "secret-1")).Info("test",
+		slog.String("Password", "secret-2"),
+		slog.Group("auth", slog.String("private_key", "secret-3"), slog.String("user", "bob")),
+		slog.Any("login", LoginResponse{Token: "secret-4"}),
+		slog.Any("user", &models.User{Username: "bob", PrivateKey: "secret-5"}),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"secret-1", "secret-2", "secret-3", "secret-4", "secret-5"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, RedactedValue)
+	assert.Contains(t, out, "bob")
+}
+
+func TestTransportHTTP_Logging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "login-token"})
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	transport, err := NewTransport(
+		WithLogger(newJSONLogger(&buf)),
+		WithHTTPClient("http://"+ts.Listener.Addr().String(), http.DefaultClient),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, transport.Login(context.Background(), "user", "password"))
+	assert.Equal(t, "login-token", transport.GetToken())
+
+	out := buf.String()
+	assert.NotContains(t, out, "login-token")
+	assert.NotContains(t, out, "password\":\"password")
+	assert.Contains(t, out, `"route":"/user/login"`)
+	assert.Contains(t, out, `"latency"`)
+	assert.Contains(t, out, `"msg":"logged in"`)
+}
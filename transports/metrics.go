@@ -0,0 +1,78 @@
+package transports
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics receives measurements about the HTTP requests made by a transport.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called once per request with the route template (e.g. /transaction/get/{txid}),
+	// the HTTP status code (0 if no response was received) and the total request latency
+	ObserveRequest(method string, route string, status int, latency time.Duration)
+	// ObserveLimiterWait is called with the time spent waiting for the request rate limiter and a
+	// concurrency limiter slot
+	ObserveLimiterWait(wait time.Duration)
+}
+
+// routeTemplates are the known API routes, with path parameters in braces
+var routeTemplates = [][]string{
+	splitRoute("/address/get/{address}/{height}"),
+	splitRoute("/address/transactions/{address}/{height}"),
+	splitRoute("/block_header/get/{block}"),
+	splitRoute("/block_header/list/{block}"),
+	splitRoute("/block_header/tip"),
+	splitRoute("/transaction/beef/{txid}"),
+	splitRoute("/transaction/from_block/lite/{subscription_id}"),
+	splitRoute("/transaction/from_block/{subscription_id}"),
+	splitRoute("/transaction/get/{txid}"),
+	splitRoute("/transaction/get/{txid}/bin"),
+	splitRoute("/transaction/proof/{txid}/bin"),
+	splitRoute("/txo/get/{outpoint}"),
+	splitRoute("/txo/spend/{outpoint}"),
+	splitRoute("/user/get"),
+	splitRoute("/user/login"),
+	splitRoute("/user/refresh-token"),
+	splitRoute("/user/subscription-token"),
+}
+
+// splitRoute splits a route into its path segments
+func splitRoute(route string) []string {
+	return strings.Split(strings.Trim(route, "/"), "/")
+}
+
+// RouteTemplate returns the route template for a request path, so that metrics are not
+// labelled with transaction IDs or addresses. Unknown paths are returned as "other".
+func RouteTemplate(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := splitRoute(path)
+
+	for _, template := range routeTemplates {
+		if matchRoute(template, segments) {
+			return "/" + strings.Join(template, "/")
+		}
+	}
+	return "other"
+}
+
+// matchRoute checks whether the path segments match a route template
+func matchRoute(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return false
+		}
+	}
+	return true
+}
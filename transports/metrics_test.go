@@ -0,0 +1,89 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	routes    []string
+	statuses  []int
+	waits     int
+	coalesced int
+}
+
+func (m *testMetrics) ObserveRequest(_ string, route string, status int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, route)
+	m.statuses = append(m.statuses, status)
+}
+
+func (m *testMetrics) ObserveLimiterWait(_ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waits++
+}
+
+func (m *testMetrics) ObserveCoalesced(_ string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.coalesced++
+}
+
+func TestRouteTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/transaction/get/abc":                      "/transaction/get/{txid}",
+		"/transaction/get/abc/bin":                  "/transaction/get/{txid}/bin",
+		"/transaction/beef/abc":                     "/transaction/beef/{txid}",
+		"/transaction/proof/abc/bin":                "/transaction/proof/{txid}/bin",
+		"/transaction/from_block/sub?height=1":      "/transaction/from_block/{subscription_id}",
+		"/transaction/from_block/lite/sub?height=1": "/transaction/from_block/lite/{subscription_id}",
+		"/address/get/1Addr/0":                      "/address/get/{address}/{height}",
+		"/address/transactions/1Addr/100":           "/address/transactions/{address}/{height}",
+		"/block_header/list/100?limit=10":           "/block_header/list/{block}",
+		"/block_header/tip":                         "/block_header/tip",
+		"/txo/spend/abc_0":                          "/txo/spend/{outpoint}",
+		"/user/subscription-token":                  "/user/subscription-token",
+		"/unknown/route":                            "other",
+		"/transaction/get/":                         "other",
+	}
+	for path, expected := range tests {
+		assert.Equal(t, expected, RouteTemplate(path), path)
+	}
+}
+
+func TestTransportHTTP_Metrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/transaction/get/missing/bin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"height":100}`))
+	}))
+	defer ts.Close()
+
+	metrics := &testMetrics{}
+	transport, err := NewTransport(
+		WithMetrics(metrics),
+		WithHTTPClient("http://"+ts.Listener.Addr().String(), http.DefaultClient),
+	)
+	require.NoError(t, err)
+
+	_, err = transport.GetChainTip(context.Background())
+	require.NoError(t, err)
+	_, err = transport.GetRawTransaction(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{"/block_header/tip", "/transaction/get/{txid}/bin"}, metrics.routes)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotFound}, metrics.statuses)
+	assert.Equal(t, 2, metrics.waits, "binary requests wait for the limiter too")
+}
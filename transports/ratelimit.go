@@ -0,0 +1,148 @@
+package transports
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateRecovery is the fraction of the configured rate an adaptive limiter recovers per
+// successful response after slowing down
+const rateRecovery = 0.01
+
+// RateLimit configures a RateLimiter
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that can be made at once after being idle
+	// (RequestsPerSecond rounded up, and at least 1, if 0)
+	Burst int
+
+	// Adaptive halves the rate when the server responds with 429 Too Many Requests, pausing
+	// for its Retry-After, then recovers gradually towards RequestsPerSecond as requests succeed
+	Adaptive bool
+
+	// MinRequestsPerSecond is the lowest rate an adaptive limiter slows down to
+	// (a tenth of RequestsPerSecond if 0)
+	MinRequestsPerSecond float64
+}
+
+// RateLimiter is a token bucket limiting the rate of HTTP requests. One limiter can be
+// shared by several clients (WithRateLimiter) to stay within a per-process quota.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	config RateLimit
+	now    func() time.Time
+
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates a rate limiter, starting with a full bucket. A rate of 0 or less
+// disables limiting.
+func NewRateLimiter(config RateLimit) *RateLimiter {
+	if config.Burst <= 0 {
+		config.Burst = max(1, int(math.Ceil(config.RequestsPerSecond)))
+	}
+	if config.MinRequestsPerSecond <= 0 || config.MinRequestsPerSecond > config.RequestsPerSecond {
+		config.MinRequestsPerSecond = config.RequestsPerSecond / 10
+	}
+	return &RateLimiter{
+		config: config,
+		now:    time.Now,
+		rate:   config.RequestsPerSecond,
+		tokens: float64(config.Burst),
+	}
+}
+
+// Rate returns the current requests per second, which an adaptive limiter lowers after
+// 429 responses
+func (l *RateLimiter) Rate() float64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Wait blocks until a request may be made or the context is cancelled
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long to wait for one
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	if !l.last.IsZero() {
+		l.tokens = min(float64(l.config.Burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// observe adjusts an adaptive limiter from a response
+func (l *RateLimiter) observe(resp *http.Response) {
+	if l == nil || !l.config.Adaptive || resp == nil || l.config.RequestsPerSecond <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		l.rate = min(l.config.RequestsPerSecond, l.rate+l.config.RequestsPerSecond*rateRecovery)
+		return
+	}
+	l.rate = max(l.config.MinRequestsPerSecond, l.rate/2)
+	l.tokens = min(l.tokens, 0)
+	if wait := retryAfter(resp, l.now()); wait > 0 {
+		l.pausedUntil = l.now().Add(wait)
+	}
+}
+
+// retryAfter parses the Retry-After header of a response, given in seconds or as a date
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("bucket", func(t *testing.T) {
+		l := NewRateLimiter(RateLimit{RequestsPerSecond: 10, Burst: 2})
+		now := time.Now()
+		l.now = func() time.Time { return now }
+
+		assert.Zero(t, l.reserve())
+		assert.Zero(t, l.reserve())
+		assert.Equal(t, 100*time.Millisecond, l.reserve(), "the burst is used up")
+		now = now.Add(100 * time.Millisecond)
+		assert.Zero(t, l.reserve())
+		now = now.Add(time.Hour)
+		assert.Zero(t, l.reserve())
+		assert.Zero(t, l.reserve())
+		assert.Positive(t, l.reserve(), "tokens never exceed the burst")
+	})
+
+	t.Run("adaptive", func(t *testing.T) {
+		l := NewRateLimiter(RateLimit{RequestsPerSecond: 100, Adaptive: true})
+		now := time.Now()
+		l.now = func() time.Time { return now }
+
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+		resp.Header.Set("Retry-After", "2")
+		l.observe(resp)
+		assert.InDelta(t, 50, l.Rate(), 0.001)
+		assert.Equal(t, 2*time.Second, l.reserve(), "paused for Retry-After")
+
+		resp.Header.Del("Retry-After")
+		for i := 0; i < 10; i++ {
+			l.observe(resp)
+		}
+		assert.InDelta(t, 10, l.Rate(), 0.001, "never below the minimum")
+
+		for i := 0; i < 200; i++ {
+			l.observe(&http.Response{StatusCode: http.StatusOK})
+		}
+		assert.InDelta(t, 100, l.Rate(), 0.001, "recovers to the configured rate")
+	})
+
+	t.Run("not adaptive", func(t *testing.T) {
+		l := NewRateLimiter(RateLimit{RequestsPerSecond: 100})
+		l.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+		assert.InDelta(t, 100, l.Rate(), 0.001)
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		l := NewRateLimiter(RateLimit{RequestsPerSecond: 0.001})
+		require.NoError(t, l.Wait(ctx))
+		cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, l.Wait(cancelled), context.DeadlineExceeded)
+
+		var disabled *RateLimiter
+		require.NoError(t, disabled.Wait(ctx))
+		require.NoError(t, NewRateLimiter(RateLimit{}).Wait(ctx))
+	})
+
+	t.Run("retry after date", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+		assert.Equal(t, time.Minute, retryAfter(resp, now))
+	})
+}
+
+func TestTransportHTTP_RateLimiter(t *testing.T) {
+	ctx := context.Background()
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write([]byte{1, 2, 3})
+	}))
+	defer ts.Close()
+
+	// One limiter shared by two transports, so only the burst gets through immediately
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 0.001, Burst: 2})
+	a, err := NewTransport(WithHTTP(ts.URL), WithRateLimiter(limiter))
+	require.NoError(t, err)
+	b, err := NewTransport(WithHTTP(ts.URL), WithRateLimiter(limiter))
+	require.NoError(t, err)
+
+	_, err = a.GetRawTransaction(ctx, "abc")
+	require.NoError(t, err)
+	_, err = b.GetProof(ctx, "abc")
+	require.NoError(t, err)
+
+	limited, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = a.GetBeef(limited, "abc")
+	require.ErrorIs(t, err, context.DeadlineExceeded, "binary requests are rate limited")
+	_, err = b.GetChainTip(limited)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
@@ -0,0 +1,386 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ServerSelection is how requests are spread across servers
+type ServerSelection int
+
+const (
+	// SelectRoundRobin sends requests to each healthy server in turn
+	SelectRoundRobin ServerSelection = iota
+	// SelectLatency picks healthy servers at random, weighted towards lower latency
+	SelectLatency
+)
+
+const (
+	// DefaultProbeInterval is how often servers are health checked
+	DefaultProbeInterval = 30 * time.Second
+	// DefaultFailureThreshold is the number of consecutive failures that mark a server down
+	DefaultFailureThreshold = 3
+)
+
+// latencySmoothing is the weight of a new sample in the moving average latency
+const latencySmoothing = 0.2
+
+// ServerPolicy configures how a transport uses several servers
+type ServerPolicy struct {
+	Selection ServerSelection
+
+	// ProbeInterval is how often each server is health checked (DefaultProbeInterval if 0).
+	// Probes run in the background as requests are made.
+	ProbeInterval time.Duration
+
+	// FailureThreshold is the number of consecutive failed requests or probes that mark a
+	// server down (DefaultFailureThreshold if 0). A down server gets no requests until a
+	// probe succeeds, unless every server is down.
+	FailureThreshold int
+}
+
+// ServerStatus is the health of one server
+type ServerStatus struct {
+	Server              string        // Host, without the scheme
+	SSL                 bool          // Whether https and wss are used
+	Healthy             bool          // Whether the server gets requests
+	Latency             time.Duration // Moving average of successful response times
+	Requests            uint64        // Requests and probes sent
+	Failures            uint64        // Requests and probes that failed
+	ConsecutiveFailures int
+	LastError           string
+	LastChecked         time.Time // Time of the last request or probe
+}
+
+// poolServer is a server and its health, guarded by the pool's lock
+type poolServer struct {
+	status    ServerStatus
+	nextProbe time.Time
+	probing   bool
+}
+
+// serverPool selects between servers and tracks their health
+type serverPool struct {
+	mu      sync.Mutex
+	servers []*poolServer
+	policy  ServerPolicy
+	next    int
+	probe   func(ctx context.Context, server string, useSSL bool) error
+}
+
+// newServerPool creates a pool of servers, all initially healthy
+func newServerPool(serverURLs []string, policy ServerPolicy) *serverPool {
+	if policy.ProbeInterval <= 0 {
+		policy.ProbeInterval = DefaultProbeInterval
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = DefaultFailureThreshold
+	}
+
+	now := time.Now()
+	p := &serverPool{policy: policy}
+	for _, serverURL := range serverURLs {
+		server, useSSL := parseServerURL(serverURL)
+		p.servers = append(p.servers, &poolServer{
+			status:    ServerStatus{Server: server, SSL: useSSL, Healthy: true},
+			nextProbe: now.Add(policy.ProbeInterval),
+		})
+	}
+	return p
+}
+
+// size returns the number of servers
+func (p *serverPool) size() int {
+	return len(p.servers)
+}
+
+// pick selects a server that is not in exclude, preferring healthy ones. It returns nil if
+// every server is excluded.
+func (p *serverPool) pick(exclude map[*poolServer]struct{}) *poolServer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var healthy, all []*poolServer
+	for _, s := range p.servers {
+		if _, ok := exclude[s]; ok {
+			continue
+		}
+		all = append(all, s)
+		if s.status.Healthy {
+			healthy = append(healthy, s)
+		}
+	}
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if p.policy.Selection == SelectLatency {
+		return pickByLatency(candidates)
+	}
+	s := candidates[p.next%len(candidates)]
+	p.next++
+	return s
+}
+
+// pickByLatency picks a server at random with a weight inversely proportional to its latency.
+// Servers without a measurement are weighted as the average server.
+func pickByLatency(candidates []*poolServer) *poolServer {
+	var total time.Duration
+	var measured int
+	for _, s := range candidates {
+		if s.status.Latency > 0 {
+			total += s.status.Latency
+			measured++
+		}
+	}
+	average := time.Millisecond
+	if measured > 0 {
+		average = total / time.Duration(measured)
+	}
+
+	weights := make([]float64, len(candidates))
+	var sum float64
+	for i, s := range candidates {
+		latency := s.status.Latency
+		if latency <= 0 {
+			latency = average
+		}
+		weights[i] = 1 / float64(max(latency, time.Microsecond))
+		sum += weights[i]
+	}
+	r := rand.Float64() * sum
+	for i, w := range weights {
+		if r < w {
+			return candidates[i]
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1]
+}
+
+// report records the outcome of a request or probe to a server
+func (p *serverPool) report(s *poolServer, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := &s.status
+	status.Requests++
+	status.LastChecked = time.Now()
+	if err == nil {
+		status.Healthy = true
+		status.ConsecutiveFailures = 0
+		if status.Latency == 0 {
+			status.Latency = latency
+		} else {
+			status.Latency += time.Duration(latencySmoothing * float64(latency-status.Latency))
+		}
+		return
+	}
+
+	status.Failures++
+	status.ConsecutiveFailures++
+	status.LastError = err.Error()
+	if status.ConsecutiveFailures >= p.policy.FailureThreshold {
+		status.Healthy = false
+	}
+}
+
+// markDown marks a server down until a probe succeeds
+func (p *serverPool) markDown(server string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.servers {
+		if s.status.Server != server {
+			continue
+		}
+		s.status.Healthy = false
+		s.status.ConsecutiveFailures = max(s.status.ConsecutiveFailures, p.policy.FailureThreshold)
+		if err != nil {
+			s.status.LastError = err.Error()
+		}
+		s.nextProbe = time.Now().Add(p.policy.ProbeInterval)
+	}
+}
+
+// address returns a server's host and whether to use SSL
+func (p *serverPool) address(s *poolServer) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return s.status.Server, s.status.SSL
+}
+
+// statuses returns a snapshot of every server's health
+func (p *serverPool) statuses() []ServerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]ServerStatus, len(p.servers))
+	for i, s := range p.servers {
+		statuses[i] = s.status
+	}
+	return statuses
+}
+
+// probeDue starts a background probe of every server whose probe interval has passed
+func (p *serverPool) probeDue() {
+	now := time.Now()
+	p.mu.Lock()
+	var due []*poolServer
+	for _, s := range p.servers {
+		if !s.probing && now.After(s.nextProbe) {
+			s.probing = true
+			due = append(due, s)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, s := range due {
+		go p.probeServer(context.Background(), s)
+	}
+}
+
+// probeAll probes every server and waits for the results
+func (p *serverPool) probeAll(ctx context.Context) []ServerStatus {
+	var wg sync.WaitGroup
+	for _, s := range p.servers {
+		wg.Add(1)
+		go func(s *poolServer) {
+			defer wg.Done()
+			p.probeServer(ctx, s)
+		}(s)
+	}
+	wg.Wait()
+	return p.statuses()
+}
+
+// probeServer health checks one server, bounded by the probe interval
+func (p *serverPool) probeServer(ctx context.Context, s *poolServer) {
+	server, useSSL := p.address(s)
+	ctx, cancel := context.WithTimeout(ctx, p.policy.ProbeInterval)
+	defer cancel()
+	start := time.Now()
+	err := p.probe(ctx, server, useSSL)
+	p.report(s, time.Since(start), err)
+
+	p.mu.Lock()
+	s.probing = false
+	s.nextProbe = time.Now().Add(p.policy.ProbeInterval)
+	p.mu.Unlock()
+}
+
+// parseServerURL splits a server URL into a host and whether to use SSL, like WithHTTP
+func parseServerURL(serverURL string) (string, bool) {
+	useSSL := !regexHTTP.MatchString(serverURL) && !regexWS.MatchString(serverURL)
+	serverURL = regexReplaceHTTPS.ReplaceAllString(serverURL, "")
+	serverURL = regexReplaceWSS.ReplaceAllString(serverURL, "")
+	return serverURL, useSSL
+}
+
+// serverError is a response status that counts as a server failure
+func serverError(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errors.New("server error: " + strconv.Itoa(resp.StatusCode) + " - " + resp.Status)
+	}
+	return nil
+}
+
+// canFailOver reports whether a request may be retried on another server
+func canFailOver(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// send submits a request to the transport's server, or with several servers to the selected
+// one, failing over to the others when an idempotent request errors or gets a 5xx response
+func (h *TransportHTTP) send(ctx context.Context, method, path string, body []byte, contentType string) (*http.Response, error) {
+	if h.pool == nil {
+		resp, err := h.sendTo(ctx, h.server, h.useSSL, method, path, body, contentType)
+		h.rate.observe(resp)
+		return resp, err
+	}
+	h.pool.probeDue()
+
+	tried := make(map[*poolServer]struct{}, h.pool.size())
+	for {
+		if len(tried) > 0 {
+			// Each attempt counts against the request rate
+			if err := h.rate.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		s := h.pool.pick(tried)
+		tried[s] = struct{}{}
+		server, useSSL := h.pool.address(s)
+
+		start := time.Now()
+		resp, err := h.sendTo(ctx, server, useSSL, method, path, body, contentType)
+		h.rate.observe(resp)
+		if ctx.Err() != nil {
+			return resp, err // cancelled by the caller, not the server's fault
+		}
+		failure := err
+		if err == nil {
+			failure = serverError(resp)
+		}
+		h.pool.report(s, time.Since(start), failure)
+		if failure == nil || !canFailOver(method) || len(tried) == h.pool.size() {
+			return resp, err
+		}
+
+		h.observeRequest(method, path, resp, start)
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		h.log().Warn("failing over", slog.String("server", server), slog.Any("error", failure))
+	}
+}
+
+// sendTo submits a request to one server
+func (h *TransportHTTP) sendTo(ctx context.Context, server string, useSSL bool, method, path string, body []byte, contentType string) (*http.Response, error) {
+	protocol := "https"
+	if !useSSL {
+		protocol = "http"
+	}
+	serverRequest := fmt.Sprintf("%s://%s/%s%s", protocol, server, h.version, path)
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, serverRequest, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("token", h.token)
+	return h.httpClient.Do(req)
+}
+
+// probeServer health checks a server by fetching the chain tip
+func (h *TransportHTTP) probeServer(ctx context.Context, server string, useSSL bool) error {
+	resp, err := h.sendTo(ctx, server, useSSL, http.MethodGet, "/block_header/tip", nil, "")
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.New("probe: " + strconv.Itoa(resp.StatusCode) + " - " + resp.Status)
+	}
+	h.log().Debug("probed server", slog.String("server", server))
+	return nil
+}
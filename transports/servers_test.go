@@ -0,0 +1,151 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer serves the chain tip with the given status and counts requests
+func newCountingServer(t *testing.T, status *int32, hits *int32) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+		_, _ = w.Write([]byte(`{"height": 1}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newPoolTransport(t *testing.T, policy ServerPolicy, urls ...string) *TransportHTTP {
+	t.Helper()
+	service, err := NewTransport(WithServers(urls, policy))
+	require.NoError(t, err)
+	return service.(*TransportHTTP)
+}
+
+func TestTransportHTTP_Servers(t *testing.T) {
+	ctx := context.Background()
+	statusA, statusB := int32(http.StatusOK), int32(http.StatusOK)
+	var hitsA, hitsB int32
+	a := newCountingServer(t, &statusA, &hitsA)
+	b := newCountingServer(t, &statusB, &hitsB)
+
+	t.Run("round robin", func(t *testing.T) {
+		transport := newPoolTransport(t, ServerPolicy{}, a.URL, b.URL)
+		for i := 0; i < 4; i++ {
+			_, err := transport.GetChainTip(ctx)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), atomic.SwapInt32(&hitsA, 0))
+		assert.Equal(t, int32(2), atomic.SwapInt32(&hitsB, 0))
+
+		statuses := transport.ServerStatus()
+		require.Len(t, statuses, 2)
+		assert.Equal(t, a.Listener.Addr().String(), statuses[0].Server)
+		assert.False(t, statuses[0].SSL)
+		assert.True(t, statuses[0].Healthy)
+		assert.Equal(t, uint64(2), statuses[0].Requests)
+		assert.Positive(t, statuses[0].Latency)
+	})
+
+	t.Run("fails over", func(t *testing.T) {
+		atomic.StoreInt32(&statusA, http.StatusBadGateway)
+		defer atomic.StoreInt32(&statusA, http.StatusOK)
+		transport := newPoolTransport(t, ServerPolicy{FailureThreshold: 2}, a.URL, b.URL)
+
+		for i := 0; i < 6; i++ {
+			_, err := transport.GetChainTip(ctx)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), atomic.SwapInt32(&hitsA, 0), "a is taken out of rotation after two failures")
+		assert.Equal(t, int32(6), atomic.SwapInt32(&hitsB, 0))
+
+		statuses := transport.ServerStatus()
+		assert.False(t, statuses[0].Healthy)
+		assert.Equal(t, uint64(2), statuses[0].Failures)
+		assert.Contains(t, statuses[0].LastError, "502")
+		assert.True(t, statuses[1].Healthy)
+
+		// A successful probe brings the server back
+		atomic.StoreInt32(&statusA, http.StatusOK)
+		statuses = transport.ProbeServers(ctx)
+		assert.True(t, statuses[0].Healthy)
+		atomic.StoreInt32(&hitsA, 0)
+		atomic.StoreInt32(&hitsB, 0)
+	})
+
+	t.Run("posts are not retried", func(t *testing.T) {
+		atomic.StoreInt32(&statusA, http.StatusBadGateway)
+		defer atomic.StoreInt32(&statusA, http.StatusOK)
+		transport := newPoolTransport(t, ServerPolicy{FailureThreshold: 10}, a.URL, b.URL)
+
+		var failed int
+		for i := 0; i < 2; i++ {
+			if _, err := transport.GetSubscriptionToken(ctx, "sub"); err != nil {
+				failed++
+			}
+		}
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, int32(1), atomic.SwapInt32(&hitsA, 0))
+		assert.Equal(t, int32(1), atomic.SwapInt32(&hitsB, 0))
+	})
+
+	t.Run("all servers down", func(t *testing.T) {
+		atomic.StoreInt32(&statusA, http.StatusServiceUnavailable)
+		atomic.StoreInt32(&statusB, http.StatusServiceUnavailable)
+		defer atomic.StoreInt32(&statusA, http.StatusOK)
+		defer atomic.StoreInt32(&statusB, http.StatusOK)
+		transport := newPoolTransport(t, ServerPolicy{FailureThreshold: 1}, a.URL, b.URL)
+
+		_, err := transport.GetChainTip(ctx)
+		require.Error(t, err)
+		_, err = transport.GetChainTip(ctx)
+		require.Error(t, err, "down servers are still tried when none is healthy")
+		assert.Equal(t, int32(4), atomic.SwapInt32(&hitsA, 0)+atomic.SwapInt32(&hitsB, 0))
+	})
+
+	t.Run("mark down and probe", func(t *testing.T) {
+		transport := newPoolTransport(t, ServerPolicy{ProbeInterval: time.Hour}, a.URL, b.URL)
+		transport.MarkServerDown(b.Listener.Addr().String(), assert.AnError)
+		for i := 0; i < 3; i++ {
+			server, useSSL := transport.SelectServer()
+			assert.Equal(t, a.Listener.Addr().String(), server)
+			assert.False(t, useSSL)
+		}
+		assert.Equal(t, assert.AnError.Error(), transport.ServerStatus()[1].LastError)
+
+		statuses := transport.ProbeServers(ctx)
+		assert.True(t, statuses[1].Healthy)
+	})
+
+	t.Run("single server", func(t *testing.T) {
+		transport := newPoolTransport(t, ServerPolicy{}, a.URL)
+		assert.Nil(t, transport.ServerStatus())
+		assert.Nil(t, transport.ProbeServers(ctx))
+		assert.Equal(t, a.Listener.Addr().String(), transport.GetServerURL())
+	})
+}
+
+func TestPickByLatency(t *testing.T) {
+	fast := &poolServer{status: ServerStatus{Server: "fast", Latency: time.Millisecond}}
+	slow := &poolServer{status: ServerStatus{Server: "slow", Latency: 9 * time.Millisecond}}
+	picks := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		picks[pickByLatency([]*poolServer{fast, slow}).status.Server]++
+	}
+	assert.Greater(t, picks["fast"], 800)
+	assert.Positive(t, picks["slow"])
+
+	pool := newServerPool([]string{"https://a", "http://b"}, ServerPolicy{Selection: SelectLatency})
+	assert.True(t, pool.servers[0].status.SSL)
+	assert.False(t, pool.servers[1].status.SSL)
+	assert.NotNil(t, pool.pick(nil), "unmeasured servers can be picked")
+}
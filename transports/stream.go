@@ -0,0 +1,130 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+)
+
+// ArrayReader decodes the elements of a JSON array response one at a time,
+// so large responses are never held in memory at once
+type ArrayReader[T any] struct {
+	body    io.ReadCloser
+	dec     *json.Decoder
+	started bool
+	done    bool
+}
+
+// NewArrayReader creates a reader for a JSON array. Close closes the body.
+func NewArrayReader[T any](body io.ReadCloser) *ArrayReader[T] {
+	return &ArrayReader[T]{body: body, dec: json.NewDecoder(body)}
+}
+
+// Next decodes the next element, returning io.EOF after the last one.
+// A JSON null is treated as an empty array.
+func (r *ArrayReader[T]) Next() (*T, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if !r.started {
+		r.started = true
+		tok, err := r.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil {
+			r.done = true
+			return nil, io.EOF
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("expected JSON array, got %v", tok)
+		}
+	}
+	if !r.dec.More() {
+		r.done = true
+		if _, err := r.dec.Token(); err != nil { // closing ]
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	item := new(T)
+	if err := r.dec.Decode(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Close closes the underlying response body
+func (r *ArrayReader[T]) Close() error {
+	r.done = true
+	return r.body.Close()
+}
+
+// StreamAddressTransactions streams the metadata of transactions related to the given address,
+// starting at fromHeight. A limit of 0 asks the server for everything.
+func (h *TransportHTTP) StreamAddressTransactions(ctx context.Context, address string, fromHeight uint32, limit uint) (*ArrayReader[models.AddressTx], error) {
+	body, err := h.doHTTPRequestStream(ctx, http.MethodGet, addressPath("/address/get/", address, fromHeight, limit))
+	if err != nil {
+		return nil, err
+	}
+	return NewArrayReader[models.AddressTx](body), nil
+}
+
+// StreamAddressTransactionDetails streams the full transactions related to the given address,
+// starting at fromHeight. A limit of 0 asks the server for everything.
+func (h *TransportHTTP) StreamAddressTransactionDetails(ctx context.Context, address string, fromHeight uint32, limit uint) (*ArrayReader[models.Transaction], error) {
+	body, err := h.doHTTPRequestStream(ctx, http.MethodGet, addressPath("/address/transactions/", address, fromHeight, limit))
+	if err != nil {
+		return nil, err
+	}
+	return NewArrayReader[models.Transaction](body), nil
+}
+
+// addressPath builds an address route with an optional limit
+func addressPath(prefix, address string, fromHeight uint32, limit uint) string {
+	path := fmt.Sprintf("%s%s/%d", prefix, address, fromHeight)
+	if limit > 0 {
+		path += "?limit=" + strconv.FormatUint(uint64(limit), 10)
+	}
+	return path
+}
+
+// doHTTPRequestStream will create and submit an HTTP request, returning the open response body.
+// The limiter slot is released once the response headers arrive, so requests can be made while
+// the body is read, even with a limit of one.
+func (h *TransportHTTP) doHTTPRequestStream(ctx context.Context, method string, path string) (io.ReadCloser, error) {
+	done, err := h.breaker.allow(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = h.acquire(ctx); err != nil {
+		done(ctx, nil, err)
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := h.send(ctx, method, path, nil, "")
+	done(ctx, resp, err)
+	h.limiter.record(ctx, RouteTemplate(path), resp, err, time.Since(start))
+	h.observeRequest(method, path, resp, start)
+	h.release()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New("server error: " + strconv.Itoa(resp.StatusCode) + " - " + resp.Status)
+	}
+
+	return resp.Body, nil
+}
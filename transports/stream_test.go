@@ -0,0 +1,89 @@
+package transports
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayReader(t *testing.T) {
+	t.Run("elements", func(t *testing.T) {
+		r := NewArrayReader[models.AddressTx](io.NopCloser(strings.NewReader(
+			`[{"transaction_id":"a","block_height":1},{"transaction_id":"b","block_height":2}]`,
+		)))
+		first, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "a", first.TransactionID)
+		second, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), second.BlockHeight)
+		_, err = r.Next()
+		assert.ErrorIs(t, err, io.EOF)
+		_, err = r.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		r := NewArrayReader[models.AddressTx](io.NopCloser(strings.NewReader(`null`)))
+		_, err := r.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("not an array", func(t *testing.T) {
+		r := NewArrayReader[models.AddressTx](io.NopCloser(strings.NewReader(`{"a":1}`)))
+		_, err := r.Next()
+		assert.Error(t, err)
+	})
+}
+
+func TestTransportHTTP_StreamAddressTransactions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/address/get/addr/10":
+			assert.Equal(t, "5", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`[{"transaction_id":"a","block_height":10}]`))
+		case "/v1/transaction/get/a":
+			_, _ = w.Write([]byte(`{"id":"a"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	transport := &TransportHTTP{
+		server:     ts.Listener.Addr().String(),
+		httpClient: http.DefaultClient,
+		version:    "v1",
+		limiter:    newConcurrencyLimiter(1),
+	}
+	ctx := context.Background()
+
+	r, err := transport.StreamAddressTransactions(ctx, "addr", 10, 5)
+	require.NoError(t, err)
+	assert.Zero(t, transport.ConcurrencyStats().InFlight, "slot is released once the headers arrive")
+	tx, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "a", tx.TransactionID)
+
+	// A request made while reading the stream gets the only slot
+	nested, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	full, err := transport.GetTransaction(nested, tx.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", full.ID)
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close())
+	assert.Zero(t, transport.ConcurrencyStats().InFlight)
+
+	_, err = transport.StreamAddressTransactionDetails(ctx, "addr", 10, 0)
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, transport.ConcurrencyStats().InFlight)
+}
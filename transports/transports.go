@@ -1,10 +1,18 @@
 package transports
 
+import "log/slog"
+
 // Client is the transport client
 type Client struct {
 	debug                 bool
 	transport             TransportService
 	maxConcurrentRequests int
+	metrics               Metrics
+	logger                *slog.Logger
+	circuitBreaker        *CircuitBreakerConfig
+	rateLimiter           *RateLimiter
+	adaptiveConcurrency   *AdaptiveConcurrency
+	noCoalescing          bool
 }
 
 // ClientOps are the client options functions
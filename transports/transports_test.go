@@ -57,3 +57,37 @@ func TestWithDebugging(t *testing.T) {
 		assert.True(t, c.IsDebug())
 	})
 }
+
+type testWrapper struct {
+	TransportService
+}
+
+func (w *testWrapper) Unwrap() TransportService {
+	return w.TransportService
+}
+
+// TestAs will test the method As()
+func TestAs(t *testing.T) {
+	service, err := NewTransport(WithHTTP("http://localhost"))
+	require.NoError(t, err)
+
+	t.Run("implemented", func(t *testing.T) {
+		servers, ok := As[ServerService](service)
+		require.True(t, ok)
+		assert.Equal(t, service, servers)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		servers, ok := As[ServerService](&testWrapper{TransportService: &testWrapper{TransportService: service}})
+		require.True(t, ok)
+		assert.Equal(t, service, servers)
+	})
+
+	t.Run("not implemented", func(t *testing.T) {
+		type embedded struct{ TransportService }
+		_, ok := As[ServerService](&embedded{TransportService: service})
+		assert.False(t, ok)
+		_, ok = As[ServerService](nil)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,61 @@
+package utxo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+)
+
+// ErrInvalidTransaction is returned when a raw transaction cannot be parsed
+var ErrInvalidTransaction = rawtx.ErrInvalidTransaction
+
+// ErrInvalidAddress is returned for addresses that are not valid base58check P2PKH addresses
+var ErrInvalidAddress = errors.New("invalid address")
+
+// p2pkhHash returns the public key hash of a pay-to-public-key-hash locking script
+func p2pkhHash(script []byte) ([]byte, bool) {
+	// OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+	if len(script) != 25 || script[0] != 0x76 || script[1] != 0xa9 || script[2] != 0x14 ||
+		script[23] != 0x88 || script[24] != 0xac {
+		return nil, false
+	}
+	return script[3:23], true
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// addressHash decodes a base58check P2PKH address and returns its public key hash
+func addressHash(address string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range []byte(address) {
+		i := bytes.IndexByte([]byte(base58Alphabet), c)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	decoded := n.Bytes()
+	for _, c := range []byte(address) {
+		if c != base58Alphabet[0] {
+			break
+		}
+		decoded = append([]byte{0}, decoded...)
+	}
+
+	// version byte, 20 byte hash, 4 byte checksum
+	if len(decoded) != 25 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return nil, fmt.Errorf("%w: bad checksum: %s", ErrInvalidAddress, address)
+	}
+	return decoded[1:21], nil
+}
@@ -0,0 +1,385 @@
+// Package utxo builds and maintains the set of unspent outputs for a group of addresses.
+//
+// The set is built from the address history (GetAddressTransactionDetails), checked with
+// spend lookups (GetSpend), and kept up to date by wrapping a subscription's event handler:
+//
+//	set, err := utxo.New(client, []string{address})
+//	err = set.Sync(ctx)
+//	sub, err := client.Subscribe(ctx, subscriptionID, fromBlock, set.EventHandler(ctx, handler))
+//	balance := set.Balance()
+//
+// Only pay-to-public-key-hash outputs are recognised.
+package utxo
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+)
+
+// maxSpendLookups is the number of concurrent spend lookups made by Sync
+const maxSpendLookups = 8
+
+// Source is the part of the JungleBus client used to build the set.
+// *junglebus.Client implements it.
+type Source interface {
+	GetAddressTransactionDetails(ctx context.Context, address string, fromHeight uint32) ([]*models.Transaction, error)
+	GetRawTransaction(ctx context.Context, txID string) ([]byte, error)
+	GetSpend(ctx context.Context, txID string, vout uint32) ([]byte, error)
+}
+
+var _ Source = (*junglebus.Client)(nil)
+
+// Outpoint identifies a transaction output
+type Outpoint struct {
+	TxID string
+	Vout uint32
+}
+
+// String returns the outpoint as txid_vout, the format used by the txo API
+func (o Outpoint) String() string {
+	return fmt.Sprintf("%s_%d", o.TxID, o.Vout)
+}
+
+// UTXO is an unspent output paying one of the tracked addresses
+type UTXO struct {
+	Outpoint
+	Address  string
+	Satoshis uint64
+	Script   []byte
+	Height   uint32 // 0 while unconfirmed
+}
+
+// Balance is the value of the unspent outputs
+type Balance struct {
+	Confirmed   uint64
+	Unconfirmed uint64
+}
+
+// Total returns the confirmed plus unconfirmed balance
+func (b Balance) Total() uint64 {
+	return b.Confirmed + b.Unconfirmed
+}
+
+// Set is the UTXO set of a group of addresses. It is safe for concurrent use.
+type Set struct {
+	mu        sync.RWMutex
+	source    Source
+	addresses map[string]string // public key hash (hex) to address
+	utxos     map[Outpoint]*UTXO
+}
+
+// New creates an empty set tracking the given P2PKH addresses. Call Sync to build it.
+func New(source Source, addresses []string) (*Set, error) {
+	if source == nil {
+		return nil, errors.New("source cannot be nil")
+	}
+	s := &Set{
+		source:    source,
+		addresses: make(map[string]string, len(addresses)),
+		utxos:     make(map[Outpoint]*UTXO),
+	}
+	for _, address := range addresses {
+		hash, err := addressHash(address)
+		if err != nil {
+			return nil, err
+		}
+		s.addresses[hex.EncodeToString(hash)] = address
+	}
+	return s, nil
+}
+
+// Addresses returns the tracked addresses
+func (s *Set) Addresses() []string {
+	addresses := make([]string, 0, len(s.addresses))
+	for _, address := range s.addresses {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Sync rebuilds the set from the full history of every tracked address. Outputs that are not
+// spent by a transaction in the history are checked with a spend lookup.
+func (s *Set) Sync(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	utxos := make(map[Outpoint]*UTXO)
+	spent := make(map[Outpoint]struct{})
+	seen := make(map[string]struct{})
+
+	for _, address := range s.Addresses() {
+		txs, err := s.source.GetAddressTransactionDetails(ctx, address, 0)
+		if err != nil {
+			return fmt.Errorf("address %s: %w", address, err)
+		}
+		for _, tx := range txs {
+			if _, ok := seen[tx.ID]; ok {
+				continue
+			}
+			seen[tx.ID] = struct{}{}
+
+			raw := tx.Transaction
+			if len(raw) == 0 {
+				if raw, err = s.source.GetRawTransaction(ctx, tx.ID); err != nil {
+					return fmt.Errorf("transaction %s: %w", tx.ID, err)
+				}
+			}
+			parsed, err := rawtx.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("transaction %s: %w", tx.ID, err)
+			}
+			for _, in := range parsed.Inputs {
+				spent[Outpoint{TxID: in.PrevTxID, Vout: in.PrevVout}] = struct{}{}
+			}
+			for _, u := range s.ownOutputs(parsed, tx.BlockHeight) {
+				utxos[u.Outpoint] = u
+			}
+		}
+	}
+
+	candidates := make([]*UTXO, 0, len(utxos))
+	for outpoint, u := range utxos {
+		if _, ok := spent[outpoint]; ok {
+			delete(utxos, outpoint)
+			continue
+		}
+		candidates = append(candidates, u)
+	}
+
+	spentRemote, err := s.lookupSpends(ctx, candidates)
+	if err != nil {
+		return err
+	}
+	for _, outpoint := range spentRemote {
+		delete(utxos, outpoint)
+	}
+
+	s.mu.Lock()
+	s.utxos = utxos
+	s.mu.Unlock()
+	return nil
+}
+
+// lookupSpends returns the candidates that the server reports as spent
+func (s *Set) lookupSpends(ctx context.Context, candidates []*UTXO) ([]Outpoint, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		spent    []Outpoint
+		firstErr error
+	)
+	sem := make(chan struct{}, maxSpendLookups)
+	for _, u := range candidates {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(outpoint Outpoint) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			data, err := s.source.GetSpend(ctx, outpoint.TxID, outpoint.Vout)
+			isSpent := false
+			if err == nil {
+				_, isSpent, err = rawtx.SpendingTxID(data)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, transports.ErrNotFound):
+			case err != nil:
+				if firstErr == nil {
+					firstErr = fmt.Errorf("spend %s: %w", outpoint, err)
+				}
+			case isSpent:
+				spent = append(spent, outpoint)
+			}
+		}(u.Outpoint)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return spent, ctx.Err()
+}
+
+// ownOutputs returns the outputs of tx paying a tracked address
+func (s *Set) ownOutputs(tx *rawtx.Tx, height uint32) []*UTXO {
+	var utxos []*UTXO
+	for vout, out := range tx.Outputs {
+		hash, ok := p2pkhHash(out.Script)
+		if !ok {
+			continue
+		}
+		address, ok := s.addresses[hex.EncodeToString(hash)]
+		if !ok {
+			continue
+		}
+		utxos = append(utxos, &UTXO{
+			Outpoint: Outpoint{TxID: tx.ID, Vout: uint32(vout)},
+			Address:  address,
+			Satoshis: out.Satoshis,
+			Script:   out.Script,
+			Height:   height,
+		})
+	}
+	return utxos
+}
+
+// Apply updates the set with a raw transaction at the given height (0 if unconfirmed):
+// outputs it spends are removed and outputs paying a tracked address are added.
+// Applying the same transaction again, e.g. when it confirms, only updates the height.
+func (s *Set) Apply(raw []byte, height uint32) error {
+	tx, err := rawtx.Parse(raw)
+	if err != nil {
+		return err
+	}
+	own := s.ownOutputs(tx, height)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, in := range tx.Inputs {
+		delete(s.utxos, Outpoint{TxID: in.PrevTxID, Vout: in.PrevVout})
+	}
+	for _, u := range own {
+		if existing, ok := s.utxos[u.Outpoint]; ok {
+			existing.Height = height
+			continue
+		}
+		s.utxos[u.Outpoint] = u
+	}
+	return nil
+}
+
+// ApplyTransaction applies a subscription transaction, fetching the raw transaction if the
+// event does not carry it (lite mode)
+func (s *Set) ApplyTransaction(ctx context.Context, tx *models.TransactionResponse) error {
+	raw := tx.GetTransaction()
+	if len(raw) == 0 {
+		var err error
+		if raw, err = s.source.GetRawTransaction(ctx, tx.GetId()); err != nil {
+			return fmt.Errorf("transaction %s: %w", tx.GetId(), err)
+		}
+	}
+	return s.Apply(raw, tx.GetBlockHeight())
+}
+
+// EventHandler returns a handler that applies every block and mempool transaction to the set
+// before calling the corresponding handler in next. Errors are passed to next.OnError.
+// Transactions fetched for lite mode events use ctx, which should be the context the
+// subscription was created with, so they stop with it.
+func (s *Set) EventHandler(ctx context.Context, next junglebus.EventHandler) junglebus.EventHandler {
+	apply := func(tx *models.TransactionResponse) {
+		if err := s.ApplyTransaction(ctx, tx); err != nil && next.OnError != nil {
+			next.OnError(fmt.Errorf("utxo: %w", err))
+		}
+	}
+
+	handler := next
+	handler.OnTransaction = func(tx *models.TransactionResponse) {
+		apply(tx)
+		if next.OnTransaction != nil {
+			next.OnTransaction(tx)
+		}
+	}
+	handler.OnMempool = func(tx *models.TransactionResponse) {
+		apply(tx)
+		if next.OnMempool != nil {
+			next.OnMempool(tx)
+		}
+	}
+	return handler
+}
+
+// Get returns the unspent output at the outpoint, if it is in the set
+func (s *Set) Get(outpoint Outpoint) (UTXO, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.utxos[outpoint]
+	if !ok {
+		return UTXO{}, false
+	}
+	return *u, true
+}
+
+// Unspent returns the unspent outputs of the given addresses (all tracked addresses if none),
+// ordered by height with unconfirmed outputs last, then by outpoint
+func (s *Set) Unspent(addresses ...string) []UTXO {
+	filter := addressFilter(addresses)
+
+	s.mu.RLock()
+	utxos := make([]UTXO, 0, len(s.utxos))
+	for _, u := range s.utxos {
+		if filter(u.Address) {
+			utxos = append(utxos, *u)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(utxos, func(i, j int) bool {
+		a, b := utxos[i], utxos[j]
+		if (a.Height == 0) != (b.Height == 0) {
+			return b.Height == 0
+		}
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		if a.TxID != b.TxID {
+			return a.TxID < b.TxID
+		}
+		return a.Vout < b.Vout
+	})
+	return utxos
+}
+
+// Balance returns the balance of the given addresses (all tracked addresses if none)
+func (s *Set) Balance(addresses ...string) Balance {
+	filter := addressFilter(addresses)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var balance Balance
+	for _, u := range s.utxos {
+		if !filter(u.Address) {
+			continue
+		}
+		if u.Height == 0 {
+			balance.Unconfirmed += u.Satoshis
+		} else {
+			balance.Confirmed += u.Satoshis
+		}
+	}
+	return balance
+}
+
+// addressFilter returns a predicate matching the given addresses, or every address if none
+func addressFilter(addresses []string) func(address string) bool {
+	if len(addresses) == 0 {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		set[address] = struct{}{}
+	}
+	return func(address string) bool {
+		_, ok := set[address]
+		return ok
+	}
+}
@@ -0,0 +1,238 @@
+package utxo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-open-io/go-junglebus"
+	"github.com/b-open-io/go-junglebus/internal/rawtx"
+	"github.com/b-open-io/go-junglebus/models"
+	"github.com/b-open-io/go-junglebus/transports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOutput is an output for buildTx
+type testOutput struct {
+	satoshis uint64
+	script   []byte
+}
+
+// buildTx serializes a transaction spending the given outpoints
+func buildTx(inputs []Outpoint, outputs []testOutput) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+	buf.WriteByte(byte(len(inputs)))
+	for _, in := range inputs {
+		hash, _ := hex.DecodeString(in.TxID)
+		for i := len(hash) - 1; i >= 0; i-- {
+			buf.WriteByte(hash[i])
+		}
+		_ = binary.Write(&buf, binary.LittleEndian, in.Vout)
+		buf.WriteByte(0) // empty unlocking script
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0xffffffff))
+	}
+	buf.WriteByte(byte(len(outputs)))
+	for _, out := range outputs {
+		_ = binary.Write(&buf, binary.LittleEndian, out.satoshis)
+		buf.WriteByte(byte(len(out.script)))
+		buf.Write(out.script)
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+// p2pkh returns the locking script for a public key hash
+func p2pkh(hash []byte) []byte {
+	return append(append([]byte{0x76, 0xa9, 0x14}, hash...), 0x88, 0xac)
+}
+
+// encodeAddress returns the mainnet base58check address for a public key hash
+func encodeAddress(hash []byte) string {
+	payload := append([]byte{0x00}, hash...)
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	payload = append(payload, second[:4]...)
+
+	n := new(big.Int).SetBytes(payload)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		encoded = append([]byte{base58Alphabet[mod.Int64()]}, encoded...)
+	}
+	for _, b := range payload {
+		if b != 0 {
+			break
+		}
+		encoded = append([]byte{base58Alphabet[0]}, encoded...)
+	}
+	return string(encoded)
+}
+
+// fakeSource serves address histories and spends from memory
+type fakeSource struct {
+	mu      sync.Mutex
+	history map[string][]*models.Transaction
+	raw     map[string][]byte
+	spends  map[Outpoint]bool
+
+	stall      bool   // GetSpend waits for ctx to be done
+	spendData  []byte // returned by GetSpend for spent outputs instead of a raw transaction ID
+	spendCalls int
+}
+
+func (f *fakeSource) GetAddressTransactionDetails(_ context.Context, address string, _ uint32) ([]*models.Transaction, error) {
+	return f.history[address], nil
+}
+
+func (f *fakeSource) GetRawTransaction(ctx context.Context, txID string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if raw, ok := f.raw[txID]; ok {
+		return raw, nil
+	}
+	return nil, transports.ErrNotFound
+}
+
+func (f *fakeSource) GetSpend(ctx context.Context, txID string, vout uint32) ([]byte, error) {
+	f.mu.Lock()
+	f.spendCalls++
+	f.mu.Unlock()
+	if f.stall {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.spends[Outpoint{TxID: txID, Vout: vout}] {
+		if f.spendData != nil {
+			return f.spendData, nil
+		}
+		return bytes.Repeat([]byte{0xab}, 32), nil
+	}
+	return nil, nil
+}
+
+func TestAddressHash(t *testing.T) {
+	hash, err := addressHash("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	require.NoError(t, err)
+	assert.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(hash))
+
+	_, err = addressHash("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMh")
+	require.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = addressHash("0OIl")
+	require.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestSet(t *testing.T) {
+	hashA := bytes.Repeat([]byte{0xaa}, 20)
+	hashB := bytes.Repeat([]byte{0xbb}, 20)
+	addrA, addrB := encodeAddress(hashA), encodeAddress(hashB)
+	external := Outpoint{TxID: hex.EncodeToString(bytes.Repeat([]byte{0x02}, 32))}
+
+	// tx1 pays A twice and B once; tx2 spends tx1:0 back to B; tx1:1 is spent by an unindexed tx
+	tx1 := buildTx([]Outpoint{external}, []testOutput{
+		{satoshis: 1000, script: p2pkh(hashA)},
+		{satoshis: 2000, script: p2pkh(hashA)},
+		{satoshis: 3000, script: p2pkh(hashB)},
+		{satoshis: 0, script: []byte{0x6a}},
+	})
+	id1 := rawtx.TxID(tx1)
+	tx2 := buildTx([]Outpoint{{TxID: id1, Vout: 0}}, []testOutput{{satoshis: 900, script: p2pkh(hashB)}})
+	id2 := rawtx.TxID(tx2)
+
+	source := &fakeSource{
+		history: map[string][]*models.Transaction{
+			addrA: {{ID: id1, Transaction: tx1, BlockHeight: 100}, {ID: id2, BlockHeight: 101}},
+			addrB: {{ID: id1, Transaction: tx1, BlockHeight: 100}, {ID: id2, BlockHeight: 101}},
+		},
+		raw:    map[string][]byte{id2: tx2},
+		spends: map[Outpoint]bool{{TxID: id1, Vout: 1}: true},
+	}
+
+	set, err := New(source, []string{addrA, addrB})
+	require.NoError(t, err)
+	require.NoError(t, set.Sync(context.Background()))
+
+	assert.Equal(t, Balance{Confirmed: 3900}, set.Balance())
+	assert.Equal(t, uint64(0), set.Balance(addrA).Total())
+	unspent := set.Unspent(addrB)
+	require.Len(t, unspent, 2)
+	assert.Equal(t, Outpoint{TxID: id1, Vout: 2}, unspent[0].Outpoint)
+	assert.Equal(t, uint32(100), unspent[0].Height)
+	assert.Equal(t, p2pkh(hashB), unspent[0].Script)
+	_, ok := set.Get(Outpoint{TxID: id1, Vout: 0})
+	assert.False(t, ok)
+
+	// Keep up to date from a subscription: a mempool payment to A, which then confirms
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	handler := set.EventHandler(ctx, junglebus.EventHandler{
+		OnMempool:     func(*models.TransactionResponse) { calls = append(calls, "mempool") },
+		OnTransaction: func(*models.TransactionResponse) { calls = append(calls, "tx") },
+		OnError:       func(err error) { errs = append(errs, err) },
+	})
+	tx3 := buildTx([]Outpoint{{TxID: id1, Vout: 2}}, []testOutput{{satoshis: 2500, script: p2pkh(hashA)}})
+	handler.OnMempool(&models.TransactionResponse{Id: rawtx.TxID(tx3), Transaction: tx3})
+	assert.Equal(t, Balance{Confirmed: 900, Unconfirmed: 2500}, set.Balance())
+
+	source.raw[rawtx.TxID(tx3)] = tx3
+	handler.OnTransaction(&models.TransactionResponse{Id: rawtx.TxID(tx3), BlockHeight: 102}) // lite event
+	assert.Equal(t, Balance{Confirmed: 3400}, set.Balance())
+	assert.Equal(t, []string{"mempool", "tx"}, calls)
+	assert.Equal(t, uint32(102), set.Unspent(addrA)[0].Height)
+	assert.Empty(t, errs)
+
+	// Lite mode fetches stop with the subscription context
+	cancel()
+	handler.OnTransaction(&models.TransactionResponse{Id: id2, BlockHeight: 103})
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], context.Canceled)
+
+	t.Run("unexpected spend response", func(t *testing.T) {
+		source.spendData = []byte("spent")
+		require.Error(t, set.Sync(context.Background()))
+	})
+}
+
+func TestSet_LookupSpendsCanceled(t *testing.T) {
+	source := &fakeSource{stall: true}
+	set, err := New(source, nil)
+	require.NoError(t, err)
+	candidates := make([]*UTXO, 3*maxSpendLookups)
+	for i := range candidates {
+		candidates[i] = &UTXO{Outpoint: Outpoint{TxID: "tx", Vout: uint32(i)}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		assert.Eventually(t, func() bool {
+			source.mu.Lock()
+			defer source.mu.Unlock()
+			return source.spendCalls == maxSpendLookups
+		}, 5*time.Second, time.Millisecond)
+		cancel()
+	}()
+	_, err = set.lookupSpends(ctx, candidates)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, maxSpendLookups, source.spendCalls, "no lookups are started once canceled")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, nil)
+	require.Error(t, err)
+	_, err = New(&fakeSource{}, []string{"not-an-address"})
+	require.ErrorIs(t, err, ErrInvalidAddress)
+}